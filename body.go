@@ -0,0 +1,150 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// JSONResponse returns an HTTPResponse with the given status code and v as
+// its JSON body.
+func JSONResponse(status int, v interface{}) HTTPResponse {
+	return HTTPResponse{
+		StatusCode: status,
+		Headers:    http.Header{"Content-Type": {"application/json"}},
+		Body:       v,
+	}
+}
+
+// BinaryResponse returns an HTTPResponse with the given status code and
+// binary body of the given content type, which is base64 encoded using
+// ModeBinary as required by mountebank.
+func BinaryResponse(status int, data []byte, contentType string) HTTPResponse {
+	return HTTPResponse{
+		StatusCode: status,
+		Headers:    http.Header{"Content-Type": {contentType}},
+		Body:       base64.StdEncoding.EncodeToString(data),
+		Mode:       ModeBinary,
+	}
+}
+
+// FileResponse returns a 200 OK HTTPResponse with the contents of the file
+// at path as its binary body, whose content type is derived from the file
+// extension, or else from the contents themselves.
+func FileResponse(path string) (HTTPResponse, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return HTTPResponse{}, err
+	}
+	ct := mime.TypeByExtension(filepath.Ext(path))
+	if ct == "" {
+		ct = http.DetectContentType(data)
+	}
+	return BinaryResponse(http.StatusOK, data, ct), nil
+}
+
+// Bytes returns the body of the response, decoding it from base64 if its
+// Mode is ModeBinary. Bodies other than strings, such as a JSON object
+// decoded from mountebank, are returned JSON encoded.
+func (r HTTPResponse) Bytes() ([]byte, error) {
+	return bodyBytes(r.Body, r.Mode == ModeBinary)
+}
+
+// BodyBytes returns the body of a request recorded by mountebank, which
+// encodes binary bodies using base64. As with mountebank, a body is binary
+// if the request has a Content-Encoding other than identity, or a
+// Content-Type such as application/octet-stream, image/* or audio/*.
+func (r HTTPRequest) BodyBytes() ([]byte, error) {
+	return bodyBytes(r.Body, isBinaryContent(r.Headers))
+}
+
+// DecodeJSONBody decodes the JSON body of the request into v, whether the
+// body is a string of JSON or the JSON value itself, as mountebank returns
+// either depending on the content of the body.
+func (r HTTPRequest) DecodeJSONBody(v interface{}) error {
+	return decodeJSONBody(r.Body, v)
+}
+
+// DecodeJSONBody decodes the JSON body of the response into v, whether the
+// body is a string of JSON or the JSON value itself.
+func (r HTTPResponse) DecodeJSONBody(v interface{}) error {
+	return decodeJSONBody(r.Body, v)
+}
+
+func decodeJSONBody(body, v interface{}) error {
+	switch t := body.(type) {
+	case nil:
+		return errors.New("empty body")
+	case string:
+		return json.Unmarshal([]byte(t), v)
+	default:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(b, v)
+	}
+}
+
+func bodyBytes(body interface{}, binary bool) ([]byte, error) {
+	switch t := body.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if binary {
+			return base64.StdEncoding.DecodeString(t)
+		}
+		return []byte(t), nil
+	default:
+		return json.Marshal(t)
+	}
+}
+
+// binaryContentTypes are the content types, or their prefixes when ending
+// with a slash, considered binary by mountebank.
+var binaryContentTypes = []string{
+	"application/octet-stream",
+	"application/ogg",
+	"application/pdf",
+	"application/zip",
+	"application/gzip",
+	"audio/",
+	"image/",
+	"video/",
+}
+
+func isBinaryContent(h http.Header) bool {
+	if enc := headerValue(h, "Content-Encoding"); enc != "" && enc != "identity" {
+		return true
+	}
+	ct, _, err := mime.ParseMediaType(headerValue(h, "Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, b := range binaryContentTypes {
+		if ct == b || strings.HasSuffix(b, "/") && strings.HasPrefix(ct, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerValue returns the first value of the named header, whose name is
+// matched case-insensitively as headers decoded from mountebank are not in
+// their canonical form.
+func headerValue(h http.Header, name string) string {
+	for k, vs := range h {
+		if strings.EqualFold(k, name) && len(vs) > 0 {
+			return vs[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+)
+
+func TestHTTPResponse_Helpers(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+
+	t.Run("should build a JSON response", func(t *testing.T) {
+		r := mbgo.JSONResponse(http.StatusCreated, map[string]interface{}{"id": 1})
+		assert.Equals(t, mbgo.HTTPResponse{
+			StatusCode: http.StatusCreated,
+			Headers:    http.Header{"Content-Type": {"application/json"}},
+			Body:       map[string]interface{}{"id": 1},
+		}, r)
+
+		b, err := r.Bytes()
+		assert.MustOk(t, err)
+		assert.Equals(t, `{"id":1}`, string(b))
+	})
+
+	t.Run("should build a base64 encoded binary response", func(t *testing.T) {
+		r := mbgo.BinaryResponse(http.StatusOK, png, "image/png")
+		assert.Equals(t, mbgo.HTTPResponse{
+			StatusCode: http.StatusOK,
+			Headers:    http.Header{"Content-Type": {"image/png"}},
+			Body:       "iVBORw0KGgo=",
+			Mode:       mbgo.ModeBinary,
+		}, r)
+
+		b, err := r.Bytes()
+		assert.MustOk(t, err)
+		assert.Equals(t, png, b)
+	})
+
+	t.Run("should build a response from a file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "mbgo")
+		assert.MustOk(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "logo")
+		assert.MustOk(t, ioutil.WriteFile(path, png, 0600))
+
+		r, err := mbgo.FileResponse(path)
+		assert.MustOk(t, err)
+		assert.Equals(t, mbgo.BinaryResponse(http.StatusOK, png, "image/png"), r)
+	})
+}
+
+func TestHTTPRequest_BodyBytes(t *testing.T) {
+	cases := []struct {
+		Description string
+		Request     mbgo.HTTPRequest
+		Expected    []byte
+	}{
+		{
+			Description: "should return text bodies as is",
+			Request: mbgo.HTTPRequest{
+				Headers: http.Header{"content-type": {"text/plain; charset=utf-8"}},
+				Body:    "aGVsbG8=",
+			},
+			Expected: []byte("aGVsbG8="),
+		},
+		{
+			Description: "should decode bodies of binary content types",
+			Request: mbgo.HTTPRequest{
+				Headers: http.Header{"content-type": {"application/octet-stream"}},
+				Body:    "aGVsbG8=",
+			},
+			Expected: []byte("hello"),
+		},
+		{
+			Description: "should decode encoded bodies",
+			Request: mbgo.HTTPRequest{
+				Headers: http.Header{"Content-Encoding": {"gzip"}, "Content-Type": {"application/json"}},
+				Body:    "aGVsbG8=",
+			},
+			Expected: []byte("hello"),
+		},
+		{
+			Description: "should return empty bodies as nil",
+			Request:     mbgo.HTTPRequest{},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.Description, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := c.Request.BodyBytes()
+			assert.MustOk(t, err)
+			assert.Equals(t, c.Expected, b)
+		})
+	}
+}
+
+func TestHTTPRequest_DecodeJSONBody(t *testing.T) {
+	type payload struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	cases := []struct {
+		Description string
+		Body        interface{}
+		Expected    payload
+		Err         error
+	}{
+		{
+			Description: "should decode a string of JSON",
+			Body:        `{"name":"foo","count":2}`,
+			Expected:    payload{Name: "foo", Count: 2},
+		},
+		{
+			Description: "should decode a JSON object",
+			Body:        map[string]interface{}{"name": "foo", "count": float64(2)},
+			Expected:    payload{Name: "foo", Count: 2},
+		},
+		{
+			Description: "should fail on an empty body",
+			Err:         errors.New("empty body"),
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.Description, func(t *testing.T) {
+			t.Parallel()
+
+			var actual payload
+			err := mbgo.HTTPRequest{Body: c.Body}.DecodeJSONBody(&actual)
+			assert.Equals(t, c.Err, err)
+			assert.Equals(t, c.Expected, actual)
+		})
+	}
+}
+
+func TestHTTPRequest_JSON_Form(t *testing.T) {
+	in := `{"method":"POST","path":"/login","form":{"scope":["a","b"],"user":"foo"}}`
+
+	var r mbgo.HTTPRequest
+	assert.MustOk(t, json.Unmarshal([]byte(in), &r))
+	assert.Equals(t, url.Values{"user": {"foo"}, "scope": {"a", "b"}}, r.Form)
+	assert.Equals(t, map[string]json.RawMessage(nil), r.Extra)
+
+	b, err := json.Marshal(r)
+	assert.MustOk(t, err)
+	assert.Equals(t, in, string(b))
+}
@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/senseyeio/mbgo/internal/rest"
@@ -20,23 +21,38 @@ import (
 
 // Client represents a native client to the mountebank REST API.
 type Client struct {
-	restCli *rest.Client
+	root       *url.URL
+	restCli    *rest.Client
+	restOpts   []rest.Option
+	validate   bool
+	redact     []string
+	decodeMode DecodeMode
+
+	// stubLocks holds a *sync.Mutex per Imposter port, serialising the
+	// stub changes made by the Client that depend on the stub indexes.
+	stubLocks sync.Map
 }
 
 // NewClient returns a new instance of *Client given its underlying
-// *http.Client restCli and base *url.URL to the mountebank API root.
+// *http.Client restCli and base *url.URL to the mountebank API root,
+// along with any optional ClientOption values.
 //
 // If nil, defaults the root *url.URL value to point to http://localhost:2525.
-func NewClient(cli *http.Client, root *url.URL) *Client {
+// The Client decodes the values returned by mountebank using DecodeStrict
+// unless another DecodeMode is set using WithDecodeMode.
+func NewClient(cli *http.Client, root *url.URL, opts ...ClientOption) *Client {
 	if root == nil {
 		root = &url.URL{
 			Scheme: "http",
 			Host:   net.JoinHostPort("localhost", "2525"),
 		}
 	}
-	return &Client{
-		restCli: rest.NewClient(cli, root),
+	c := &Client{root: root}
+	for _, opt := range opts {
+		opt(c)
 	}
+	c.restCli = rest.NewClient(cli, root, c.restOpts...)
+	return c
 }
 
 // errorDTO represents the structure of an error received from the mountebank API.
@@ -62,10 +78,20 @@ func (cli *Client) decodeError(body io.ReadCloser) error {
 // Create creates a single new Imposter given its creation details imp.
 //
 // Note that the Imposter.RequestCount field is not used during creation.
+// If the Client was created using WithValidation, imp is checked using
+// Imposter.Validate before it is sent.
 //
 // See more information on this resource at:
 // http://www.mbtest.org/docs/api/overview#post-imposters.
 func (cli *Client) Create(ctx context.Context, imp Imposter) (*Imposter, error) {
+	ctx = withOperation(ctx, Operation{Name: "Create", Port: imp.Port, Proto: imp.Proto})
+
+	if cli.validate {
+		if err := imp.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	p := "/imposters"
 	b, err := json.Marshal(&imp)
 	if err != nil {
@@ -83,7 +109,7 @@ func (cli *Client) Create(ctx context.Context, imp Imposter) (*Imposter, error)
 	}
 
 	if resp.StatusCode == http.StatusCreated {
-		if err := cli.restCli.DecodeResponseBody(resp.Body, &imp); err != nil {
+		if err := cli.restCli.DecodeResponseBody(resp.Body, &modalImposter{&imp, cli.decodeMode}); err != nil {
 			return nil, err
 		}
 	} else {
@@ -95,13 +121,16 @@ func (cli *Client) Create(ctx context.Context, imp Imposter) (*Imposter, error)
 
 // Imposter retrieves the Imposter data at the given port.
 //
-// Note that the Imposter.RecordRequests and Imposter.AllowCORS fields
-// are ignored when un-marshalling an Imposter value and should only be
-// used when creating an Imposter.
+// The returned Imposter includes its RecordRequests, AllowCORS and
+// DefaultResponse settings, where the DefaultResponse is of the response
+// type of its protocol, e.g. *HTTPResponse for "http" Imposters. When
+// replay is true it can therefore be passed back to Create without loss.
 //
 // See more information about this resource at:
 // http://www.mbtest.org/docs/api/overview#get-imposter.
 func (cli *Client) Imposter(ctx context.Context, port int, replay bool) (*Imposter, error) {
+	ctx = withOperation(ctx, Operation{Name: "Imposter", Port: port})
+
 	p := fmt.Sprintf("/imposters/%d", port)
 	vs := url.Values{}
 	vs.Add("replayable", strconv.FormatBool(replay))
@@ -118,7 +147,7 @@ func (cli *Client) Imposter(ctx context.Context, port int, replay bool) (*Impost
 
 	var imp Imposter
 	if resp.StatusCode == http.StatusOK {
-		if err := cli.restCli.DecodeResponseBody(resp.Body, &imp); err != nil {
+		if err := cli.restCli.DecodeResponseBody(resp.Body, &modalImposter{&imp, cli.decodeMode}); err != nil {
 			return nil, err
 		}
 	} else {
@@ -128,12 +157,81 @@ func (cli *Client) Imposter(ctx context.Context, port int, replay bool) (*Impost
 	return &imp, nil
 }
 
+// UnmatchedRequests returns the requests recorded by the Imposter on the given
+// port that were not matched by any of its Stubs, and were therefore sent its
+// DefaultResponse, which helps detecting missing Stubs. The underlying type
+// of the requests is *HTTPRequest or *TCPRequest depending on the protocol of
+// the Imposter.
+//
+// Note that the Imposter must record requests and mountebank must be started
+// using the --debug option for the Stub matches to be recorded, without which
+// every recorded request is returned.
+func (cli *Client) UnmatchedRequests(ctx context.Context, port int) ([]interface{}, error) {
+	imp, err := cli.Imposter(ctx, port, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// count the matches of each request by its JSON form, as the same
+	// request is recorded both by the Imposter and the Stub it matched
+	matched := make(map[string]int)
+	for _, stub := range imp.Stubs {
+		for _, m := range stub.Matches {
+			key, err := matchKey(m.Request)
+			if err != nil {
+				return nil, err
+			}
+			matched[key]++
+		}
+	}
+
+	var unmatched []interface{}
+	for _, req := range imp.Requests {
+		key, err := matchKey(req)
+		if err != nil {
+			return nil, err
+		}
+		if matched[key] > 0 {
+			matched[key]--
+			continue
+		}
+		unmatched = append(unmatched, req)
+	}
+	return unmatched, nil
+}
+
+// matchKey returns the JSON form of a recorded request without its timestamp,
+// which mountebank only sets on the requests recorded by the Imposter and not
+// on those recorded by the Stub matches.
+func matchKey(req interface{}) (string, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return "", err
+	}
+	delete(fields, "timestamp")
+
+	// map keys are marshalled in order, so equal requests have equal keys
+	b, err = json.Marshal(fields)
+	return string(b), err
+}
+
 // AddStub adds a new Stub without restarting its Imposter given the imposter's
 // port and the new stub's index, or simply to the end of the array if index < 0.
 //
 // See more information about this resource at:
 // http://www.mbtest.org/docs/api/overview#add-stub
 func (cli *Client) AddStub(ctx context.Context, port, index int, stub Stub) (*Imposter, error) {
+	ctx = withOperation(ctx, Operation{Name: "AddStub", Port: port})
+	defer cli.lockStubs(port)()
+
+	return cli.addStub(ctx, port, index, stub)
+}
+
+func (cli *Client) addStub(ctx context.Context, port, index int, stub Stub) (*Imposter, error) {
 	p := fmt.Sprintf("/imposters/%d/stubs", port)
 
 	dto := map[string]interface{}{"stub": stub}
@@ -157,7 +255,7 @@ func (cli *Client) AddStub(ctx context.Context, port, index int, stub Stub) (*Im
 
 	var imp Imposter
 	if resp.StatusCode == http.StatusOK {
-		if err := cli.restCli.DecodeResponseBody(resp.Body, &imp); err != nil {
+		if err := cli.restCli.DecodeResponseBody(resp.Body, &modalImposter{&imp, cli.decodeMode}); err != nil {
 			return nil, err
 		}
 	} else {
@@ -172,6 +270,13 @@ func (cli *Client) AddStub(ctx context.Context, port, index int, stub Stub) (*Im
 // See more information about this resouce at:
 // http://www.mbtest.org/docs/api/overview#change-stub
 func (cli *Client) OverwriteStub(ctx context.Context, port, index int, stub Stub) (*Imposter, error) {
+	ctx = withOperation(ctx, Operation{Name: "OverwriteStub", Port: port})
+	defer cli.lockStubs(port)()
+
+	return cli.overwriteStub(ctx, port, index, stub)
+}
+
+func (cli *Client) overwriteStub(ctx context.Context, port, index int, stub Stub) (*Imposter, error) {
 	p := fmt.Sprintf("/imposters/%d/stubs/%d", port, index)
 
 	b, err := json.Marshal(stub)
@@ -191,7 +296,7 @@ func (cli *Client) OverwriteStub(ctx context.Context, port, index int, stub Stub
 
 	var imp Imposter
 	if resp.StatusCode == http.StatusOK {
-		if err := cli.restCli.DecodeResponseBody(resp.Body, &imp); err != nil {
+		if err := cli.restCli.DecodeResponseBody(resp.Body, &modalImposter{&imp, cli.decodeMode}); err != nil {
 			return nil, err
 		}
 	} else {
@@ -205,6 +310,9 @@ func (cli *Client) OverwriteStub(ctx context.Context, port, index int, stub Stub
 // See more information about this resource at:
 // http://www.mbtest.org/docs/api/overview#change-stubs
 func (cli *Client) OverwriteAllStubs(ctx context.Context, port int, stubs []Stub) (*Imposter, error) {
+	ctx = withOperation(ctx, Operation{Name: "OverwriteAllStubs", Port: port})
+	defer cli.lockStubs(port)()
+
 	p := fmt.Sprintf("/imposters/%d/stubs", port)
 
 	b, err := json.Marshal(map[string]interface{}{
@@ -226,7 +334,7 @@ func (cli *Client) OverwriteAllStubs(ctx context.Context, port int, stubs []Stub
 
 	var imp Imposter
 	if resp.StatusCode == http.StatusOK {
-		if err := cli.restCli.DecodeResponseBody(resp.Body, &imp); err != nil {
+		if err := cli.restCli.DecodeResponseBody(resp.Body, &modalImposter{&imp, cli.decodeMode}); err != nil {
 			return nil, err
 		}
 	} else {
@@ -240,8 +348,18 @@ func (cli *Client) OverwriteAllStubs(ctx context.Context, port int, stubs []Stub
 // See more information about this resource at:
 // http://www.mbtest.org/docs/api/overview#delete-stub
 func (cli *Client) RemoveStub(ctx context.Context, port, index int) (*Imposter, error) {
+	ctx = withOperation(ctx, Operation{Name: "RemoveStub", Port: port})
+	defer cli.lockStubs(port)()
+
+	return cli.removeStub(ctx, port, index)
+}
+
+func (cli *Client) removeStub(ctx context.Context, port, index int) (*Imposter, error) {
 	p := fmt.Sprintf("/imposters/%d/stubs/%d", port, index)
 
+	// removing a stub by its index is not idempotent, as a retry after the
+	// first attempt succeeded would remove the stub that followed it
+	ctx = rest.WithoutRetry(ctx)
 	req, err := cli.restCli.NewRequest(ctx, http.MethodDelete, p, http.NoBody, nil)
 	if err != nil {
 		return nil, err
@@ -254,7 +372,7 @@ func (cli *Client) RemoveStub(ctx context.Context, port, index int) (*Imposter,
 
 	var imp Imposter
 	if resp.StatusCode == http.StatusOK {
-		if err := cli.restCli.DecodeResponseBody(resp.Body, &imp); err != nil {
+		if err := cli.restCli.DecodeResponseBody(resp.Body, &modalImposter{&imp, cli.decodeMode}); err != nil {
 			return nil, err
 		}
 	} else {
@@ -263,6 +381,78 @@ func (cli *Client) RemoveStub(ctx context.Context, port, index int) (*Imposter,
 	return &imp, nil
 }
 
+// OverwriteStubByID overwrites the Stub with the given ID without restarting
+// its Imposter, using its index at the time of the call. The ID of stub is set
+// to id if empty. Stub changes made by the Client to the same Imposter that
+// depend on the stub indexes are serialised with the call, so that the index
+// cannot be changed by them between being resolved and used.
+//
+// A *StubNotFoundError is returned if no Stub has the given ID.
+func (cli *Client) OverwriteStubByID(ctx context.Context, port int, id string, stub Stub) (*Imposter, error) {
+	ctx = withOperation(ctx, Operation{Name: "OverwriteStubByID", Port: port})
+	defer cli.lockStubs(port)()
+
+	index, err := cli.stubIndex(ctx, port, id)
+	if err != nil {
+		return nil, err
+	}
+	if stub.ID == "" {
+		stub.ID = id
+	}
+	return cli.overwriteStub(ctx, port, index, stub)
+}
+
+// RemoveStubByID removes the Stub with the given ID without restarting its
+// Imposter, using its index at the time of the call, which is serialised with
+// other stub changes made by the Client to the same Imposter as described by
+// OverwriteStubByID.
+//
+// A *StubNotFoundError is returned if no Stub has the given ID.
+func (cli *Client) RemoveStubByID(ctx context.Context, port int, id string) (*Imposter, error) {
+	ctx = withOperation(ctx, Operation{Name: "RemoveStubByID", Port: port})
+	defer cli.lockStubs(port)()
+
+	index, err := cli.stubIndex(ctx, port, id)
+	if err != nil {
+		return nil, err
+	}
+	return cli.removeStub(ctx, port, index)
+}
+
+// StubNotFoundError is returned when no Stub of an Imposter has a given ID.
+type StubNotFoundError struct {
+	Port int
+	ID   string
+}
+
+// Error satisfies the error interface.
+func (e *StubNotFoundError) Error() string {
+	return fmt.Sprintf("no stub with id %q in imposter %d", e.ID, e.Port)
+}
+
+// stubIndex returns the current index of the Stub with the given ID.
+func (cli *Client) stubIndex(ctx context.Context, port int, id string) (int, error) {
+	imp, err := cli.Imposter(ctx, port, true)
+	if err != nil {
+		return 0, err
+	}
+	for i, stub := range imp.Stubs {
+		if stub.ID == id {
+			return i, nil
+		}
+	}
+	return 0, &StubNotFoundError{Port: port, ID: id}
+}
+
+// lockStubs locks the stubs of the Imposter on the given port for changes
+// made by the Client, returning the function unlocking them.
+func (cli *Client) lockStubs(port int) func() {
+	v, _ := cli.stubLocks.LoadOrStore(port, new(sync.Mutex))
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
 // Delete removes an Imposter configured on the given port and returns
 // the deleted Imposter data, or an empty Imposter struct if one does not
 // exist on the port.
@@ -270,6 +460,8 @@ func (cli *Client) RemoveStub(ctx context.Context, port, index int) (*Imposter,
 // See more information about this resource at:
 // http://www.mbtest.org/docs/api/overview#delete-imposter.
 func (cli *Client) Delete(ctx context.Context, port int, replay bool) (*Imposter, error) {
+	ctx = withOperation(ctx, Operation{Name: "Delete", Port: port})
+
 	p := fmt.Sprintf("/imposters/%d", port)
 	vs := url.Values{}
 	vs.Add("replayable", strconv.FormatBool(replay))
@@ -286,7 +478,7 @@ func (cli *Client) Delete(ctx context.Context, port int, replay bool) (*Imposter
 
 	var imp Imposter
 	if resp.StatusCode == http.StatusOK {
-		if err := cli.restCli.DecodeResponseBody(resp.Body, &imp); err != nil {
+		if err := cli.restCli.DecodeResponseBody(resp.Body, &modalImposter{&imp, cli.decodeMode}); err != nil {
 			return nil, err
 		}
 	} else {
@@ -303,6 +495,8 @@ func (cli *Client) Delete(ctx context.Context, port int, replay bool) (*Imposter
 // See more information about this resource at:
 // http://www.mbtest.org/docs/api/overview#delete-imposter-requests.
 func (cli *Client) DeleteRequests(ctx context.Context, port int) (*Imposter, error) {
+	ctx = withOperation(ctx, Operation{Name: "DeleteRequests", Port: port})
+
 	p := fmt.Sprintf("/imposters/%d/savedProxyResponses", port)
 
 	req, err := cli.restCli.NewRequest(ctx, http.MethodDelete, p, nil, nil)
@@ -317,7 +511,7 @@ func (cli *Client) DeleteRequests(ctx context.Context, port int) (*Imposter, err
 
 	var imp Imposter
 	if resp.StatusCode == http.StatusOK {
-		if err := cli.restCli.DecodeResponseBody(resp.Body, &imp); err != nil {
+		if err := cli.restCli.DecodeResponseBody(resp.Body, &modalImposter{&imp, cli.decodeMode}); err != nil {
 			return nil, err
 		}
 	} else {
@@ -327,7 +521,29 @@ func (cli *Client) DeleteRequests(ctx context.Context, port int) (*Imposter, err
 }
 
 type imposterListWrapper struct {
-	Imposters []Imposter `json:"imposters"`
+	Imposters []Imposter
+	mode      DecodeMode
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface, decoding the
+// Imposters using the DecodeMode of the wrapper.
+func (w *imposterListWrapper) UnmarshalJSON(b []byte) error {
+	var dto struct {
+		Imposters []json.RawMessage `json:"imposters"`
+	}
+	if err := json.Unmarshal(b, &dto); err != nil {
+		return err
+	}
+	if dto.Imposters == nil {
+		return nil
+	}
+	w.Imposters = make([]Imposter, len(dto.Imposters))
+	for i, raw := range dto.Imposters {
+		if err := w.Imposters[i].unmarshal(raw, w.mode); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Overwrite is used to overwrite all registered Imposters with a new
@@ -338,6 +554,22 @@ type imposterListWrapper struct {
 // See more information about this resource at:
 // http://www.mbtest.org/docs/api/overview#put-imposters.
 func (cli *Client) Overwrite(ctx context.Context, imps []Imposter) ([]Imposter, error) {
+	ctx = withOperation(ctx, Operation{Name: "Overwrite"})
+
+	if cli.validate {
+		var v validator
+		for i, imp := range imps {
+			sub := validator{}
+			sub.imposter(imp)
+			for _, fe := range sub.errs {
+				v.add(pathIndex("/imposters", i)+fe.Path, "%s", fe.Reason)
+			}
+		}
+		if err := v.err(); err != nil {
+			return nil, err
+		}
+	}
+
 	p := "/imposters"
 
 	b, err := json.Marshal(&struct {
@@ -359,7 +591,7 @@ func (cli *Client) Overwrite(ctx context.Context, imps []Imposter) ([]Imposter,
 		return nil, err
 	}
 
-	var wrap imposterListWrapper
+	wrap := imposterListWrapper{mode: cli.decodeMode}
 	if resp.StatusCode == http.StatusOK {
 		if err := cli.restCli.DecodeResponseBody(resp.Body, &wrap); err != nil {
 			return nil, err
@@ -375,6 +607,8 @@ func (cli *Client) Overwrite(ctx context.Context, imps []Imposter) ([]Imposter,
 // See more information about this resource at:
 // http://www.mbtest.org/docs/api/overview#get-imposters.
 func (cli *Client) Imposters(ctx context.Context, replay bool) ([]Imposter, error) {
+	ctx = withOperation(ctx, Operation{Name: "Imposters"})
+
 	p := "/imposters"
 	vs := url.Values{}
 	vs.Add("replayable", strconv.FormatBool(replay))
@@ -389,7 +623,7 @@ func (cli *Client) Imposters(ctx context.Context, replay bool) ([]Imposter, erro
 		return nil, err
 	}
 
-	var wrap imposterListWrapper
+	wrap := imposterListWrapper{mode: cli.decodeMode}
 	if resp.StatusCode == http.StatusOK {
 		if err := cli.restCli.DecodeResponseBody(resp.Body, &wrap); err != nil {
 			return nil, err
@@ -407,6 +641,8 @@ func (cli *Client) Imposters(ctx context.Context, replay bool) ([]Imposter, erro
 // See more information about this resource at:
 // http://www.mbtest.org/docs/api/overview#delete-imposters.
 func (cli *Client) DeleteAll(ctx context.Context, replay bool) ([]Imposter, error) {
+	ctx = withOperation(ctx, Operation{Name: "DeleteAll"})
+
 	p := "/imposters"
 	vs := url.Values{}
 	vs.Add("replayable", strconv.FormatBool(replay))
@@ -421,7 +657,7 @@ func (cli *Client) DeleteAll(ctx context.Context, replay bool) ([]Imposter, erro
 		return nil, err
 	}
 
-	var wrap imposterListWrapper
+	wrap := imposterListWrapper{mode: cli.decodeMode}
 	if resp.StatusCode == http.StatusOK {
 		if err := cli.restCli.DecodeResponseBody(resp.Body, &wrap); err != nil {
 			return nil, err
@@ -476,6 +712,8 @@ type Config struct {
 // See more information on this resource at:
 // http://www.mbtest.org/docs/api/overview#get-config.
 func (cli *Client) Config(ctx context.Context) (*Config, error) {
+	ctx = withOperation(ctx, Operation{Name: "Config"})
+
 	p := "/config"
 
 	req, err := cli.restCli.NewRequest(ctx, http.MethodGet, p, nil, nil)
@@ -499,6 +737,30 @@ func (cli *Client) Config(ctx context.Context) (*Config, error) {
 	return &cfg, nil
 }
 
+// readyPollInterval is the delay between attempts made by Client.WaitReady.
+const readyPollInterval = 100 * time.Millisecond
+
+// WaitReady blocks until mountebank answers a Client.Config call, polling
+// it until the context is done, in which case the last error is returned.
+// This is useful for tests that start mountebank alongside themselves, for
+// instance in a container, to avoid racing its startup.
+func (cli *Client) WaitReady(ctx context.Context) error {
+	for {
+		_, err := cli.Config(ctx)
+		if err == nil {
+			return nil
+		}
+
+		timer := time.NewTimer(readyPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("mountebank not ready: %v", err)
+		case <-timer.C:
+		}
+	}
+}
+
 // Log represents a log entry value in mountebank.
 //
 // See more information about its full structure at:
@@ -517,6 +779,8 @@ type Log struct {
 // See more information on this resource at:
 // http://www.mbtest.org/docs/api/overview#get-logs.
 func (cli *Client) Logs(ctx context.Context, start, end int) ([]Log, error) {
+	ctx = withOperation(ctx, Operation{Name: "Logs"})
+
 	p := "/logs"
 	vs := url.Values{}
 	if start >= 0 {
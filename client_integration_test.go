@@ -9,6 +9,7 @@ package mbgo_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"testing"
@@ -35,6 +36,22 @@ func newContext(timeout time.Duration) context.Context {
 	return ctx
 }
 
+// withoutLinks removes the hypermedia links added by mountebank to the given
+// Imposter and its stubs, which depend on its host, so that it can be compared
+// with an expected value.
+func withoutLinks(imp *mbgo.Imposter) {
+	if imp == nil {
+		return
+	}
+	delete(imp.Extra, "_links")
+	if len(imp.Extra) == 0 {
+		imp.Extra = nil
+	}
+	for i := range imp.Stubs {
+		imp.Stubs[i].Self = ""
+	}
+}
+
 func TestClient_Logs_Integration(t *testing.T) {
 	mb := newMountebankClient()
 
@@ -361,6 +378,7 @@ func TestClient_Create_Integration(t *testing.T) {
 			} else {
 				assert.Ok(t, err)
 			}
+			withoutLinks(actual)
 			assert.Equals(t, c.Expected, actual)
 
 			if c.After != nil {
@@ -442,7 +460,7 @@ func TestClient_Imposter_Integration(t *testing.T) {
 				Port:           8080,
 				Proto:          "tcp",
 				Name:           "imposter_test",
-				RecordRequests: false, // this field is only used for creation
+				RecordRequests: true,
 				RequestCount:   0,
 				Stubs: []mbgo.Stub{
 					{
@@ -480,6 +498,12 @@ func TestClient_Imposter_Integration(t *testing.T) {
 			} else {
 				assert.Ok(t, err)
 			}
+			if actual != nil && !c.Replay {
+				for i, stub := range actual.Stubs {
+					assert.Equals(t, fmt.Sprintf("http://localhost:2525/imposters/%d/stubs/%d", c.Port, i), stub.Self)
+				}
+			}
+			withoutLinks(actual)
 			assert.Equals(t, c.Expected, actual)
 
 			if c.After != nil {
@@ -624,6 +648,7 @@ func TestClient_AddStub_Integration(t *testing.T) {
 			} else {
 				assert.Ok(t, err)
 			}
+			withoutLinks(actual)
 			assert.Equals(t, c.Expected, actual)
 
 			if c.After != nil {
@@ -750,6 +775,7 @@ func TestClient_OverwriteStub_Integration(t *testing.T) {
 			} else {
 				assert.Ok(t, err)
 			}
+			withoutLinks(actual)
 			assert.Equals(t, c.Expected, actual)
 
 			if c.After != nil {
@@ -912,6 +938,7 @@ func TestClient_OverwriteAllStubs_Integration(t *testing.T) {
 			} else {
 				assert.Ok(t, err)
 			}
+			withoutLinks(actual)
 			assert.Equals(t, c.Expected, actual)
 
 			if c.After != nil {
@@ -1018,6 +1045,7 @@ func TestClient_RemoveStub_Integration(t *testing.T) {
 			} else {
 				assert.Ok(t, err)
 			}
+			withoutLinks(actual)
 			assert.Equals(t, c.Expected, actual)
 
 			if c.After != nil {
@@ -1027,6 +1055,82 @@ func TestClient_RemoveStub_Integration(t *testing.T) {
 	}
 }
 
+func TestClient_StubByID_Integration(t *testing.T) {
+	mb := newMountebankClient()
+
+	_, err := mb.DeleteAll(newContext(time.Second), false)
+	assert.MustOk(t, err)
+
+	stub := func(id, path string, code int) mbgo.Stub {
+		return mbgo.Stub{
+			ID:         id,
+			Predicates: []mbgo.Predicate{mbgo.Equals(mbgo.HTTPRequest{Path: path})},
+			Responses: []mbgo.Response{
+				{
+					Type:  "is",
+					Value: mbgo.HTTPResponse{StatusCode: code},
+				},
+			},
+		}
+	}
+	ids := func(imp *mbgo.Imposter) []string {
+		out := make([]string, len(imp.Stubs))
+		for i, s := range imp.Stubs {
+			out[i] = s.ID
+		}
+		return out
+	}
+	send := func(path string) int {
+		resp, err := http.Get("http://localhost:8080" + path)
+		assert.MustOk(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	_, err = mb.Create(newContext(time.Second), mbgo.Imposter{
+		Port:  8080,
+		Proto: "http",
+		Name:  "stub_id_test",
+	})
+	assert.MustOk(t, err)
+	_, err = mb.AddStub(newContext(time.Second), 8080, -1, stub("a", "/a", http.StatusAccepted))
+	assert.MustOk(t, err)
+	_, err = mb.AddStub(newContext(time.Second), 8080, -1, stub("b", "/b", http.StatusNotFound))
+	assert.MustOk(t, err)
+
+	t.Run("should return the stub ids of a replayable imposter", func(t *testing.T) {
+		imp, err := mb.Imposter(newContext(time.Second), 8080, true)
+		assert.MustOk(t, err)
+		assert.Equals(t, []string{"a", "b"}, ids(imp))
+	})
+
+	t.Run("should overwrite a stub by its id", func(t *testing.T) {
+		imp, err := mb.OverwriteStubByID(newContext(time.Second), 8080, "b", stub("", "/b", http.StatusCreated))
+		assert.MustOk(t, err)
+		assert.Equals(t, []string{"a", "b"}, ids(imp))
+		assert.Equals(t, http.StatusCreated, send("/b"))
+	})
+
+	t.Run("should remove a stub by its id", func(t *testing.T) {
+		imp, err := mb.RemoveStubByID(newContext(time.Second), 8080, "a")
+		assert.MustOk(t, err)
+		assert.Equals(t, []string{"b"}, ids(imp))
+		assert.Equals(t, http.StatusOK, send("/a"))
+
+		imp, err = mb.Imposter(newContext(time.Second), 8080, true)
+		assert.MustOk(t, err)
+		assert.Equals(t, []string{"b"}, ids(imp))
+	})
+
+	t.Run("should error if no stub has the id", func(t *testing.T) {
+		_, err := mb.RemoveStubByID(newContext(time.Second), 8080, "a")
+		assert.Equals(t, &mbgo.StubNotFoundError{Port: 8080, ID: "a"}, err)
+	})
+
+	_, err = mb.Delete(newContext(time.Second), 8080, false)
+	assert.MustOk(t, err)
+}
+
 func TestClient_Delete_Integration(t *testing.T) {
 	mb := newMountebankClient()
 
@@ -1067,6 +1171,7 @@ func TestClient_Delete_Integration(t *testing.T) {
 			} else {
 				assert.Ok(t, err)
 			}
+			withoutLinks(actual)
 			assert.Equals(t, c.Expected, actual)
 
 			if c.After != nil {
@@ -1122,10 +1227,11 @@ func TestClient_DeleteRequests_Integration(t *testing.T) {
 			},
 			Port: 8080,
 			Expected: &mbgo.Imposter{
-				Port:         8080,
-				Proto:        "http",
-				Name:         "delete_requests_test",
-				RequestCount: 0,
+				Port:           8080,
+				Proto:          "http",
+				Name:           "delete_requests_test",
+				RecordRequests: true,
+				RequestCount:   0,
 			},
 		},
 	}
@@ -1156,6 +1262,7 @@ func TestClient_DeleteRequests_Integration(t *testing.T) {
 					actual.Requests[i] = req
 				}
 
+				withoutLinks(actual)
 				assert.Equals(t, c.Expected, actual)
 			}
 
@@ -1295,6 +1402,9 @@ func TestClient_Imposters_Integration(t *testing.T) {
 			} else {
 				assert.Ok(t, err)
 			}
+			for i := range actual {
+				withoutLinks(&actual[i])
+			}
 			assert.Equals(t, c.Expected, actual)
 
 			if c.After != nil {
@@ -1303,3 +1413,122 @@ func TestClient_Imposters_Integration(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_Sync_Integration(t *testing.T) {
+	mb := newMountebankClient()
+
+	_, err := mb.DeleteAll(newContext(time.Second), false)
+	assert.MustOk(t, err)
+
+	stub := func(code int) mbgo.Stub {
+		return mbgo.Stub{
+			Responses: []mbgo.Response{
+				{
+					Type:  "is",
+					Value: mbgo.HTTPResponse{StatusCode: code},
+				},
+			},
+		}
+	}
+
+	desired := []mbgo.Imposter{
+		{
+			Port:  8080,
+			Proto: "http",
+			Name:  "sync_test",
+			Stubs: []mbgo.Stub{stub(http.StatusOK)},
+		},
+	}
+
+	t.Run("should create missing imposters", func(t *testing.T) {
+		plan, err := mb.Sync(newContext(time.Second), desired, false)
+		assert.MustOk(t, err)
+		assert.Equals(t, "create imposter 8080 (http, 1 stubs)", plan.String())
+	})
+
+	t.Run("should only plan stub changes in dry-run mode", func(t *testing.T) {
+		desired[0].Stubs = append(desired[0].Stubs, stub(http.StatusNotFound))
+
+		plan, err := mb.Sync(newContext(time.Second), desired, true)
+		assert.MustOk(t, err)
+		assert.Equals(t, "add stub 1 to imposter 8080", plan.String())
+
+		imp, err := mb.Imposter(newContext(time.Second), 8080, true)
+		assert.MustOk(t, err)
+		assert.Equals(t, 1, len(imp.Stubs))
+	})
+
+	t.Run("should apply stub changes without recreating the imposter", func(t *testing.T) {
+		plan, err := mb.Sync(newContext(time.Second), desired, false)
+		assert.MustOk(t, err)
+		assert.Equals(t, "add stub 1 to imposter 8080", plan.String())
+
+		plan, err = mb.Sync(newContext(time.Second), desired, true)
+		assert.MustOk(t, err)
+		assert.Equals(t, "no changes", plan.String())
+	})
+
+	_, err = mb.Delete(newContext(time.Second), 8080, false)
+	assert.MustOk(t, err)
+}
+
+func TestClient_WaitReady_Integration(t *testing.T) {
+	t.Run("should return once mountebank answers", func(t *testing.T) {
+		mb := newMountebankClient()
+		assert.Ok(t, mb.WaitReady(newContext(time.Second)))
+	})
+
+	t.Run("should error once the context is done if mountebank never answers", func(t *testing.T) {
+		mb := mbgo.NewClient(&http.Client{}, &url.URL{
+			Scheme: "http",
+			Host:   "localhost:1",
+		})
+		err := mb.WaitReady(newContext(time.Millisecond * 250))
+		assert.Equals(t, true, err != nil)
+	})
+}
+
+func TestScenario_Integration(t *testing.T) {
+	mb := newMountebankClient()
+
+	_, err := mb.DeleteAll(newContext(time.Second), false)
+	assert.MustOk(t, err)
+	_, err = mb.Create(newContext(time.Second), mbgo.Imposter{Port: 8080, Proto: "http"})
+	assert.MustOk(t, err)
+
+	order := func(method string) mbgo.Predicate {
+		return mbgo.Equals(mbgo.HTTPRequest{Method: method, Path: "/order"})
+	}
+	s, err := mb.AddScenario(newContext(time.Second), 8080, "order", "Started", []mbgo.ScenarioStep{
+		{State: "Started", Predicates: []mbgo.Predicate{order(http.MethodGet)}, Response: mbgo.HTTPResponse{StatusCode: http.StatusNotFound}},
+		{Predicates: []mbgo.Predicate{order(http.MethodPost)}, Response: mbgo.HTTPResponse{StatusCode: http.StatusCreated}, Next: "Ordered"},
+		{State: "Ordered", Predicates: []mbgo.Predicate{order(http.MethodGet)}, Response: mbgo.HTTPResponse{StatusCode: http.StatusOK}},
+	})
+	assert.MustOk(t, err)
+
+	send := func(method string) int {
+		req, err := http.NewRequest(method, "http://localhost:8080/order", nil)
+		assert.MustOk(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		assert.MustOk(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	state, err := s.State(newContext(time.Second))
+	assert.MustOk(t, err)
+	assert.Equals(t, "Started", state)
+	assert.Equals(t, http.StatusNotFound, send(http.MethodGet))
+
+	assert.Equals(t, http.StatusCreated, send(http.MethodPost))
+	assert.Equals(t, http.StatusOK, send(http.MethodGet))
+	state, err = s.State(newContext(time.Second))
+	assert.MustOk(t, err)
+	assert.Equals(t, "Ordered", state)
+
+	assert.MustOk(t, s.Reset(newContext(time.Second)))
+	assert.Equals(t, http.StatusNotFound, send(http.MethodGet))
+
+	_, err = mb.Delete(newContext(time.Second), 8080, false)
+	assert.MustOk(t, err)
+}
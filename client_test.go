@@ -0,0 +1,139 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+)
+
+// respondByPath returns a Middleware that never sends requests to mountebank,
+// recording them and responding with the body registered for their method
+// and path, or a 404 Not Found response if there is none.
+func respondByPath(reqs *[]*http.Request, bodies map[string]string) mbgo.Middleware {
+	return func(next mbgo.RoundTripFunc) mbgo.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			body, ok := bodies[req.Method+" "+req.URL.Path]
+			if !ok {
+				var ignored []*http.Request
+				return respondWith(&ignored, http.StatusNotFound, `{"errors":[{"code":"no such resource","message":"not found"}]}`)(next)(req)
+			}
+			return respondWith(reqs, http.StatusOK, body)(next)(req)
+		}
+	}
+}
+
+func TestClient_StubByID(t *testing.T) {
+	imposter := `{"port":8080,"protocol":"http","stubs":[
+		{"id":"first","responses":[{"is":{"statusCode":200}}]},
+		{"id":"second","responses":[{"is":{"statusCode":201}}]}
+	]}`
+
+	t.Run("should overwrite the stub at the current index of the ID", func(t *testing.T) {
+		var reqs []*http.Request
+		mb := mbgo.NewClient(http.DefaultClient, nil, mbgo.WithMiddleware(respondByPath(&reqs, map[string]string{
+			"GET /imposters/8080":         imposter,
+			"PUT /imposters/8080/stubs/1": imposter,
+		})))
+
+		_, err := mb.OverwriteStubByID(context.Background(), 8080, "second", mbgo.Stub{
+			Responses: []mbgo.Response{{Type: "is", Value: mbgo.HTTPResponse{StatusCode: http.StatusAccepted}}},
+		})
+		assert.MustOk(t, err)
+		assert.Equals(t, 2, len(reqs))
+		assert.Equals(t, "replayable=true", reqs[0].URL.RawQuery)
+
+		body, err := reqs[1].GetBody()
+		assert.MustOk(t, err)
+		var stub mbgo.Stub
+		assert.MustOk(t, json.NewDecoder(body).Decode(&stub))
+		assert.Equals(t, "second", stub.ID)
+	})
+
+	t.Run("should remove the stub at the current index of the ID", func(t *testing.T) {
+		var reqs []*http.Request
+		mb := mbgo.NewClient(http.DefaultClient, nil, mbgo.WithMiddleware(respondByPath(&reqs, map[string]string{
+			"GET /imposters/8080":            imposter,
+			"DELETE /imposters/8080/stubs/0": imposter,
+		})))
+
+		_, err := mb.RemoveStubByID(context.Background(), 8080, "first")
+		assert.MustOk(t, err)
+		assert.Equals(t, 2, len(reqs))
+	})
+
+	t.Run("should return an error if no stub has the ID", func(t *testing.T) {
+		var reqs []*http.Request
+		mb := mbgo.NewClient(http.DefaultClient, nil, mbgo.WithMiddleware(respondByPath(&reqs, map[string]string{
+			"GET /imposters/8080": imposter,
+		})))
+
+		_, err := mb.RemoveStubByID(context.Background(), 8080, "third")
+		var notFound *mbgo.StubNotFoundError
+		assert.Equals(t, true, errors.As(err, &notFound))
+		assert.Equals(t, `no stub with id "third" in imposter 8080`, err.Error())
+		assert.Equals(t, 1, len(reqs))
+	})
+}
+
+func TestClient_UnmatchedRequests(t *testing.T) {
+	var reqs []*http.Request
+	mb := mbgo.NewClient(http.DefaultClient, nil, mbgo.WithMiddleware(respondByPath(&reqs, map[string]string{
+		"GET /imposters/8080": `{
+			"port": 8080,
+			"protocol": "http",
+			"numberOfRequests": 3,
+			"requests": [
+				{"method": "GET", "path": "/foo", "timestamp": "2018-10-10T09:00:00.000Z"},
+				{"method": "GET", "path": "/bar", "timestamp": "2018-10-10T09:00:01.000Z"},
+				{"method": "GET", "path": "/foo", "timestamp": "2018-10-10T09:00:02.000Z"}
+			],
+			"stubs": [
+				{
+					"predicates": [{"equals": {"path": "/foo"}}],
+					"responses": [{"is": {"statusCode": 200}}],
+					"matches": [
+						{
+							"timestamp": "2018-10-10T09:00:00.001Z",
+							"request": {"method": "GET", "path": "/foo"},
+							"response": {"statusCode": 200}
+						},
+						{
+							"timestamp": "2018-10-10T09:00:02.001Z",
+							"request": {"method": "GET", "path": "/foo"},
+							"response": {"statusCode": 200}
+						}
+					]
+				}
+			]
+		}`,
+	})))
+
+	imp, err := mb.Imposter(context.Background(), 8080, false)
+	assert.MustOk(t, err)
+	assert.Equals(t, []mbgo.Match{
+		{
+			Timestamp: "2018-10-10T09:00:00.001Z",
+			Request:   &mbgo.HTTPRequest{Method: http.MethodGet, Path: "/foo"},
+			Response:  &mbgo.HTTPResponse{StatusCode: http.StatusOK},
+		},
+		{
+			Timestamp: "2018-10-10T09:00:02.001Z",
+			Request:   &mbgo.HTTPRequest{Method: http.MethodGet, Path: "/foo"},
+			Response:  &mbgo.HTTPResponse{StatusCode: http.StatusOK},
+		},
+	}, imp.Stubs[0].Matches)
+
+	unmatched, err := mb.UnmatchedRequests(context.Background(), 8080)
+	assert.MustOk(t, err)
+	assert.Equals(t, []interface{}{
+		&mbgo.HTTPRequest{Method: http.MethodGet, Path: "/bar", Timestamp: "2018-10-10T09:00:01.000Z"},
+	}, unmatched)
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ChangeKind is the kind of difference described by a Change.
+type ChangeKind int
+
+// The supported ChangeKind values.
+const (
+	// ChangeModified means the value exists in both Imposters but differs.
+	ChangeModified ChangeKind = iota
+
+	// ChangeAdded means the value only exists in the second Imposter.
+	ChangeAdded
+
+	// ChangeRemoved means the value only exists in the first Imposter.
+	ChangeRemoved
+)
+
+// Change is a single difference between two Imposters found by Diff.
+type Change struct {
+	// Kind is the kind of the Change.
+	Kind ChangeKind
+
+	// Path is the JSON-pointer-like location of the changed value, using
+	// the mountebank JSON field names, e.g. "/stubs/0/responses/1/is/statusCode".
+	Path string
+
+	// A and B are the normalised JSON values before and after the Change;
+	// A is nil for ChangeAdded and B is nil for ChangeRemoved.
+	A, B interface{}
+}
+
+// String satisfies the fmt.Stringer interface, rendering the Change in a
+// single line such as `~ /name: "foo" => "bar"`.
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s: %s", c.Path, diffValue(c.B))
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s: %s", c.Path, diffValue(c.A))
+	default:
+		return fmt.Sprintf("~ %s: %s => %s", c.Path, diffValue(c.A), diffValue(c.B))
+	}
+}
+
+func diffValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%#v", v)
+	}
+	return string(b)
+}
+
+// FormatDiff renders the Change values returned by Diff one per line, which
+// is suitable for use in test failure messages.
+func FormatDiff(changes []Change) string {
+	ss := make([]string, len(changes))
+	for i, c := range changes {
+		ss[i] = c.String()
+	}
+	return strings.Join(ss, "\n")
+}
+
+// Diff returns the structural differences between the Imposters a and b,
+// ordered by path, or nil if they are equivalent.
+//
+// The Imposters are compared in their mountebank JSON form, so a Predicate
+// request of type *HTTPRequest is equivalent to the same HTTPRequest value,
+// or to its raw JSON before the protocol is known. Header names are compared
+// case-insensitively, query parameter values regardless of their order, and
+// string bodies containing JSON as their decoded value. Values that cannot
+// be marshalled into JSON are always reported as modified.
+func Diff(a, b Imposter) []Change {
+	var changes []Change
+	diffRecurse("", normaliseImposter(a), normaliseImposter(b), &changes)
+	return changes
+}
+
+// normaliseImposter converts an Imposter into its generic JSON form,
+// including the recorded requests omitted when creating an Imposter.
+//
+// The default response, stubs and extra fields are normalised on their own,
+// so a value that cannot be marshalled only affects its own location.
+func normaliseImposter(imp Imposter) map[string]interface{} {
+	settings := imp
+	settings.DefaultResponse, settings.Stubs, settings.Extra = nil, nil, nil
+	settings.Requests, settings.RequestCount = nil, 0
+
+	// the remaining settings are plain values, which always marshal
+	out := normaliseValue(settings).(map[string]interface{})
+
+	for k, raw := range imp.Extra {
+		if _, ok := out[k]; !ok {
+			out[k] = normaliseValue(raw)
+		}
+	}
+	if imp.DefaultResponse != nil {
+		out["defaultResponse"] = normaliseValue(imp.DefaultResponse)
+	}
+	if len(imp.Stubs) > 0 {
+		stubs := make([]interface{}, len(imp.Stubs))
+		for i, stub := range imp.Stubs {
+			stubs[i] = normaliseValue(stub)
+		}
+		out["stubs"] = stubs
+	}
+
+	if imp.RequestCount != 0 {
+		out["numberOfRequests"] = float64(imp.RequestCount)
+	}
+	if len(imp.Requests) > 0 {
+		reqs := make([]interface{}, len(imp.Requests))
+		for i, r := range imp.Requests {
+			reqs[i] = normaliseValue(r)
+		}
+		out["requests"] = reqs
+	}
+
+	return normaliseJSON("", out).(map[string]interface{})
+}
+
+// unmarshalable stands in for a value that cannot be marshalled into JSON.
+// It is always referenced by pointer, so that such values never compare as
+// equivalent to anything, not even to themselves.
+type unmarshalable struct {
+	Error string `json:"error"`
+}
+
+// normaliseValue converts any value into its generic JSON form.
+func normaliseValue(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return &unmarshalable{Error: err.Error()}
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return &unmarshalable{Error: err.Error()}
+	}
+	return out
+}
+
+// normaliseJSON rewrites the generic JSON value v found under key so that
+// equivalent values compare equal.
+func normaliseJSON(key string, v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, sub := range t {
+			if k == "_links" {
+				// hypermedia links are added by mountebank and do not
+				// describe the behaviour of an Imposter
+				continue
+			}
+			if key == "headers" {
+				k = http.CanonicalHeaderKey(k)
+			}
+			out[k] = normaliseJSON(k, sub)
+		}
+		return out
+
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, sub := range t {
+			out[i] = normaliseJSON("", sub)
+		}
+		return out
+
+	case string:
+		if key == "body" {
+			s := strings.TrimSpace(t)
+			if strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[") {
+				var decoded interface{}
+				if err := json.Unmarshal([]byte(s), &decoded); err == nil {
+					return normaliseJSON("", decoded)
+				}
+			}
+		}
+		return t
+
+	default:
+		return t
+	}
+}
+
+// diffRecurse appends the differences between the generic JSON values a and
+// b found at path to changes.
+func diffRecurse(path string, a, b interface{}, changes *[]Change) {
+	switch at := a.(type) {
+	case map[string]interface{}:
+		bt, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		parent := path[strings.LastIndex(path, "/")+1:]
+		keys := make([]string, 0, len(at)+len(bt))
+		for k := range at {
+			keys = append(keys, k)
+		}
+		for k := range bt {
+			if _, ok := at[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			sub := path + "/" + k
+			av, aok := at[k]
+			bv, bok := bt[k]
+			switch {
+			case !aok:
+				*changes = append(*changes, Change{Kind: ChangeAdded, Path: sub, B: bv})
+			case !bok:
+				*changes = append(*changes, Change{Kind: ChangeRemoved, Path: sub, A: av})
+			case parent == "query":
+				diffQuery(sub, av, bv, changes)
+			default:
+				diffRecurse(sub, av, bv, changes)
+			}
+		}
+		return
+
+	case []interface{}:
+		bt, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(at) || i < len(bt); i++ {
+			sub := pathIndex(path, i)
+			switch {
+			case i >= len(at):
+				*changes = append(*changes, Change{Kind: ChangeAdded, Path: sub, B: bt[i]})
+			case i >= len(bt):
+				*changes = append(*changes, Change{Kind: ChangeRemoved, Path: sub, A: at[i]})
+			default:
+				diffRecurse(sub, at[i], bt[i], changes)
+			}
+		}
+		return
+
+	default:
+		if a == b {
+			return
+		}
+	}
+
+	*changes = append(*changes, Change{Kind: ChangeModified, Path: path, A: a, B: b})
+}
+
+// diffQuery compares the values of a single query parameter regardless of
+// their order, as either a single string or an array of strings.
+func diffQuery(path string, a, b interface{}, changes *[]Change) {
+	if !equalStrings(queryValues(a), queryValues(b)) {
+		*changes = append(*changes, Change{Kind: ChangeModified, Path: path, A: a, B: b})
+	}
+}
+
+func queryValues(v interface{}) []string {
+	var ss []string
+	switch t := v.(type) {
+	case []interface{}:
+		for _, elem := range t {
+			ss = append(ss, fmt.Sprint(elem))
+		}
+	default:
+		ss = append(ss, fmt.Sprint(t))
+	}
+	sort.Strings(ss)
+	return ss
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+)
+
+func TestDiff(t *testing.T) {
+	created := mbgo.Imposter{
+		Port:  8080,
+		Proto: "http",
+		Name:  "foo",
+		Stubs: []mbgo.Stub{
+			{
+				Predicates: []mbgo.Predicate{
+					{
+						Operator: "equals",
+						Request: mbgo.HTTPRequest{
+							Path:    "/foo",
+							Query:   url.Values{"page": {"1", "2"}},
+							Headers: http.Header{"Accept": {"application/json"}},
+						},
+					},
+				},
+				Responses: []mbgo.Response{
+					{Type: "is", Value: mbgo.HTTPResponse{StatusCode: http.StatusOK, Body: `{"ok": true}`}},
+				},
+			},
+		},
+	}
+
+	t.Run("should treat equivalent representations as equal", func(t *testing.T) {
+		retrieved := mbgo.Imposter{
+			Port:  8080,
+			Proto: "http",
+			Name:  "foo",
+			Stubs: []mbgo.Stub{
+				{
+					Predicates: []mbgo.Predicate{
+						{
+							Operator: "equals",
+							Request:  json.RawMessage(`{"path":"/foo","query":{"page":["2","1"]},"headers":{"accept":"application/json"}}`),
+						},
+					},
+					Responses: []mbgo.Response{
+						{Type: "is", Value: &mbgo.HTTPResponse{StatusCode: http.StatusOK, Body: map[string]interface{}{"ok": true}}},
+					},
+				},
+			},
+		}
+		assert.Equals(t, []mbgo.Change(nil), mbgo.Diff(created, retrieved))
+	})
+
+	t.Run("should report path-annotated changes", func(t *testing.T) {
+		retrieved := mbgo.Imposter{
+			Port:         8080,
+			Proto:        "http",
+			RequestCount: 1,
+			Requests: []interface{}{
+				&mbgo.HTTPRequest{Method: http.MethodGet, Path: "/foo"},
+			},
+			Stubs: []mbgo.Stub{
+				{
+					Predicates: []mbgo.Predicate{
+						{
+							Operator: "equals",
+							Request: &mbgo.HTTPRequest{
+								Path:    "/foo",
+								Query:   url.Values{"page": {"1"}},
+								Headers: http.Header{"Accept": {"application/json"}},
+							},
+						},
+					},
+					Responses: []mbgo.Response{
+						{Type: "is", Value: &mbgo.HTTPResponse{StatusCode: http.StatusNotFound, Body: `{"ok": true}`}},
+					},
+				},
+			},
+		}
+
+		changes := mbgo.Diff(created, retrieved)
+		assert.Equals(t, []mbgo.Change{
+			{Kind: mbgo.ChangeRemoved, Path: "/name", A: "foo"},
+			{Kind: mbgo.ChangeAdded, Path: "/numberOfRequests", B: float64(1)},
+			{Kind: mbgo.ChangeAdded, Path: "/requests", B: []interface{}{
+				map[string]interface{}{"method": "GET", "path": "/foo"},
+			}},
+			{Kind: mbgo.ChangeModified, Path: "/stubs/0/predicates/0/equals/query/page", A: []interface{}{"1", "2"}, B: "1"},
+			{Kind: mbgo.ChangeModified, Path: "/stubs/0/responses/0/is/statusCode", A: float64(200), B: float64(404)},
+		}, changes)
+
+		assert.Equals(t, `- /name: "foo"
++ /numberOfRequests: 1
++ /requests: [{"method":"GET","path":"/foo"}]
+~ /stubs/0/predicates/0/equals/query/page: ["1","2"] => "1"
+~ /stubs/0/responses/0/is/statusCode: 200 => 404`, mbgo.FormatDiff(changes))
+	})
+	t.Run("should never treat values that cannot be marshalled as equivalent", func(t *testing.T) {
+		broken := mbgo.Imposter{
+			Port:  8080,
+			Proto: "http",
+			Name:  "foo",
+			Stubs: []mbgo.Stub{
+				{Responses: []mbgo.Response{{Type: "is", Value: make(chan int)}}},
+			},
+		}
+		renamed := broken
+		renamed.Name = "bar"
+
+		changes := mbgo.Diff(broken, renamed)
+		paths := make([]string, len(changes))
+		for i, c := range changes {
+			paths[i] = c.Path
+		}
+		assert.Equals(t, []string{"/name", "/stubs/0"}, paths)
+		assert.Equals(t, 1, len(mbgo.Diff(broken, broken)))
+	})
+}
@@ -9,18 +9,61 @@ import (
 	"fmt"
 	"net"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
-func parseClientSocket(s string) (ip net.IP, err error) {
-	parts := strings.Split(s, ":")
-	ipStr := strings.Join(parts[0:len(parts)-1], ":")
+// parseClientSocket parses the address of a client in the form "ip:port"
+// used by mountebank, where the IP address may be an unbracketed IPv6 one.
+// IPv4 addresses in the IPv4-mapped IPv6 form, which mountebank reports for
+// IPv4 clients of dual-stack sockets, keep that form when formatted again.
+func parseClientSocket(s string) (net.Addr, net.IP, error) {
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return nil, nil, fmt.Errorf("missing port in address: %s", s)
+	}
 
-	ip = net.ParseIP(ipStr)
+	ip := net.ParseIP(s[:i])
 	if ip == nil {
-		err = fmt.Errorf("invalid IP address: %s", ipStr)
+		return nil, nil, fmt.Errorf("invalid IP address: %s", s[:i])
+	}
+	port, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid port: %s", s[i+1:])
+	}
+	addr := &net.TCPAddr{IP: ip, Port: port}
+	if ip.To4() != nil && strings.Contains(s[:i], ":") {
+		return mappedAddr{addr}, ip, nil
+	}
+	return addr, ip, nil
+}
+
+// mappedAddr is the address of an IPv4 client reported by mountebank in the
+// IPv4-mapped IPv6 form, e.g. "::ffff:172.17.0.1:56912".
+type mappedAddr struct {
+	*net.TCPAddr
+}
+
+// String satisfies the net.Addr interface, formatting the address in its
+// IPv4-mapped IPv6 form.
+func (a mappedAddr) String() string {
+	return fmt.Sprintf("::ffff:%s:%d", a.IP, a.Port)
+}
+
+// formatClientSocket formats the address of a client in the form used by
+// mountebank, falling back to the IP address alone if addr is nil.
+func formatClientSocket(addr net.Addr, ip net.IP) string {
+	switch t := addr.(type) {
+	case nil:
+		if ip == nil {
+			return ""
+		}
+		return ip.String()
+	case *net.TCPAddr:
+		return fmt.Sprintf("%s:%d", t.IP, t.Port)
+	default:
+		return t.String()
 	}
-	return
 }
 
 func toMapValues(q map[string][]string) map[string]interface{} {
@@ -59,24 +102,101 @@ func fromMapValues(q map[string]interface{}) (map[string][]string, error) {
 			for i, elem := range typ {
 				s, ok := elem.(string)
 				if !ok {
-					return nil, errors.New("invalid query key array subtype")
+					return nil, fmt.Errorf("invalid value type for key %q: %#v", k, elem)
 				}
 				ss[i] = s
 			}
 			out[k] = ss
 		default:
-			return nil, fmt.Errorf("invalid query key type: %#v", typ)
+			return nil, fmt.Errorf("invalid value type for key %q: %#v", k, typ)
 		}
 	}
 
 	return out, nil
 }
 
+// extraFields returns the fields of the JSON object b whose keys are not
+// known, or nil if there are none.
+func extraFields(b []byte, known ...string) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for _, key := range known {
+		delete(fields, key)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// withExtraFields adds the extra fields to the JSON object b, without
+// overriding any of the fields it already has.
+func withExtraFields(b []byte, extra map[string]json.RawMessage) ([]byte, error) {
+	if len(extra) == 0 {
+		return b, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for key, v := range extra {
+		if _, ok := fields[key]; !ok {
+			fields[key] = v
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// fieldDecoder handles the fields of a JSON object that fail to decode
+// according to a DecodeMode.
+type fieldDecoder struct {
+	mode  DecodeMode
+	raw   map[string]json.RawMessage
+	extra map[string]json.RawMessage
+	err   error
+}
+
+// newFieldDecoder returns a fieldDecoder for the JSON object b, whose extra
+// fields are those that are not known.
+func newFieldDecoder(b []byte, mode DecodeMode, known ...string) (*fieldDecoder, error) {
+	d := &fieldDecoder{mode: mode}
+	if err := json.Unmarshal(b, &d.raw); err != nil {
+		return nil, err
+	}
+	var err error
+	d.extra, err = extraFields(b, known...)
+	return d, err
+}
+
+// fail records the error decoding the field with the given key, which is
+// returned by DecodeStrict, whereas DecodeLenient keeps its raw value as
+// an extra field.
+func (d *fieldDecoder) fail(key string, err error) {
+	if d.mode == DecodeLenient {
+		if d.extra == nil {
+			d.extra = make(map[string]json.RawMessage)
+		}
+		d.extra[key] = d.raw[key]
+		return
+	}
+	if d.err == nil {
+		d.err = fmt.Errorf("invalid %s: %v", key, err)
+	}
+}
+
+// modeUnmarshaler is implemented by the values decoded according to a DecodeMode.
+type modeUnmarshaler interface {
+	unmarshal(b []byte, mode DecodeMode) error
+}
+
 type httpRequestDTO struct {
 	RequestFrom string                 `json:"requestFrom,omitempty"`
 	Method      string                 `json:"method,omitempty"`
 	Path        string                 `json:"path,omitempty"`
 	Query       map[string]interface{} `json:"query,omitempty"`
+	Form        map[string]interface{} `json:"form,omitempty"`
 	Headers     map[string]interface{} `json:"headers,omitempty"`
 	Body        interface{}            `json:"body,omitempty"`
 	Timestamp   string                 `json:"timestamp,omitempty"`
@@ -89,43 +209,82 @@ func (r HTTPRequest) MarshalJSON() ([]byte, error) {
 		Method:      r.Method,
 		Path:        r.Path,
 		Query:       toMapValues(r.Query),
+		Form:        toMapValues(r.Form),
 		Headers:     toMapValues(r.Headers),
 		Body:        r.Body,
 		Timestamp:   r.Timestamp,
 	}
-	if r.RequestFrom != nil {
-		dto.RequestFrom = r.RequestFrom.String()
+	dto.RequestFrom = formatClientSocket(r.RemoteAddr, r.RequestFrom)
+	b, err := json.Marshal(dto)
+	if err != nil {
+		return nil, err
 	}
-	return json.Marshal(dto)
+	return withExtraFields(b, r.Extra)
 }
 
-// UnmarshalJSON satisfies the json.Unmarshaler interface.
+// UnmarshalJSON satisfies the json.Unmarshaler interface, using DecodeStrict.
 func (r *HTTPRequest) UnmarshalJSON(b []byte) error {
+	return r.unmarshal(b, DecodeStrict)
+}
+
+func (r *HTTPRequest) unmarshal(b []byte, mode DecodeMode) error {
 	var v httpRequestDTO
 	err := json.Unmarshal(b, &v)
 	if err != nil {
 		return err
 	}
+	d, err := newFieldDecoder(b, mode, "requestFrom", "method", "path", "query", "form", "headers", "body", "timestamp")
+	if err != nil {
+		return err
+	}
 
 	if v.RequestFrom != "" {
-		r.RequestFrom, err = parseClientSocket(v.RequestFrom)
+		addr, ip, err := parseClientSocket(v.RequestFrom)
 		if err != nil {
-			return nil
+			d.fail("requestFrom", err)
+		} else {
+			r.RemoteAddr, r.RequestFrom = addr, ip
 		}
 	}
 	r.Method = v.Method
 	r.Path = v.Path
-	r.Query, err = fromMapValues(v.Query)
-	if err != nil {
-		return nil
+	if r.Query, err = fromMapValues(v.Query); err != nil {
+		d.fail("query", err)
 	}
-	r.Headers, err = fromMapValues(v.Headers)
-	if err != nil {
-		return nil
+	if r.Form, err = fromMapValues(v.Form); err != nil {
+		d.fail("form", err)
+	}
+	if r.Headers, err = fromMapValues(v.Headers); err != nil {
+		d.fail("headers", err)
 	}
 	r.Body = v.Body
 	r.Timestamp = v.Timestamp
+	r.Extra = d.extra
 
+	return d.err
+}
+
+type httpExistsRequestDTO struct {
+	Method  *bool           `json:"method,omitempty"`
+	Path    *bool           `json:"path,omitempty"`
+	Body    *bool           `json:"body,omitempty"`
+	Query   map[string]bool `json:"query,omitempty"`
+	Form    map[string]bool `json:"form,omitempty"`
+	Headers map[string]bool `json:"headers,omitempty"`
+}
+
+// MarshalJSON satisfies the json.Marshaler interface.
+func (r HTTPExistsRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(httpExistsRequestDTO(r))
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+func (r *HTTPExistsRequest) UnmarshalJSON(b []byte) error {
+	var v httpExistsRequestDTO
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*r = HTTPExistsRequest(v)
 	return nil
 }
 
@@ -138,31 +297,43 @@ type httpResponseDTO struct {
 
 // MarshalJSON satisfies the json.Marshaler interface.
 func (r HTTPResponse) MarshalJSON() ([]byte, error) {
-	return json.Marshal(httpResponseDTO{
+	b, err := json.Marshal(httpResponseDTO{
 		StatusCode: r.StatusCode,
 		Headers:    toMapValues(r.Headers),
 		Body:       r.Body,
 		Mode:       r.Mode,
 	})
+	if err != nil {
+		return nil, err
+	}
+	return withExtraFields(b, r.Extra)
 }
 
-// UnmarshalJSON satisfies the json.Unmarshaler interface.
+// UnmarshalJSON satisfies the json.Unmarshaler interface, using DecodeStrict.
 func (r *HTTPResponse) UnmarshalJSON(b []byte) error {
+	return r.unmarshal(b, DecodeStrict)
+}
+
+func (r *HTTPResponse) unmarshal(b []byte, mode DecodeMode) error {
 	var v httpResponseDTO
 	err := json.Unmarshal(b, &v)
 	if err != nil {
 		return err
 	}
-
-	r.StatusCode = v.StatusCode
-	r.Headers, err = fromMapValues(v.Headers)
+	d, err := newFieldDecoder(b, mode, "statusCode", "headers", "body", "_mode")
 	if err != nil {
 		return err
 	}
+
+	r.StatusCode = v.StatusCode
+	if r.Headers, err = fromMapValues(v.Headers); err != nil {
+		d.fail("headers", err)
+	}
 	r.Body = v.Body
 	r.Mode = v.Mode
+	r.Extra = d.extra
 
-	return nil
+	return d.err
 }
 
 type tcpRequestDTO struct {
@@ -176,14 +347,16 @@ func (r TCPRequest) MarshalJSON() ([]byte, error) {
 		RequestFrom: "",
 		Data:        r.Data,
 	}
-	if r.RequestFrom != nil {
-		dto.RequestFrom = r.RequestFrom.String()
-	}
+	dto.RequestFrom = formatClientSocket(r.RemoteAddr, r.RequestFrom)
 	return json.Marshal(dto)
 }
 
-// UnmarshalJSON satisfies the json.Unmarshaler interface.
+// UnmarshalJSON satisfies the json.Unmarshaler interface, using DecodeStrict.
 func (r *TCPRequest) UnmarshalJSON(b []byte) error {
+	return r.unmarshal(b, DecodeStrict)
+}
+
+func (r *TCPRequest) unmarshal(b []byte, mode DecodeMode) error {
 	var v tcpRequestDTO
 	err := json.Unmarshal(b, &v)
 	if err != nil {
@@ -191,14 +364,18 @@ func (r *TCPRequest) UnmarshalJSON(b []byte) error {
 	}
 
 	if v.RequestFrom != "" {
-		r.RequestFrom, err = parseClientSocket(v.RequestFrom)
-		if err != nil {
-			return err
+		addr, ip, err := parseClientSocket(v.RequestFrom)
+		if err == nil {
+			r.RemoteAddr, r.RequestFrom = addr, ip
+		} else if mode == DecodeStrict {
+			// TCPRequest has no extra fields to keep the raw value in,
+			// so it is dropped when using DecodeLenient
+			return fmt.Errorf("invalid requestFrom: %v", err)
 		}
 	}
 	r.Data = v.Data
 
-	return err
+	return nil
 }
 
 type tcpResponseDTO struct {
@@ -207,11 +384,15 @@ type tcpResponseDTO struct {
 
 // MarshalJSON satisfies the json.Marshaler interface.
 func (r TCPResponse) MarshalJSON() ([]byte, error) {
-	return json.Marshal(tcpResponseDTO(r))
+	return json.Marshal(tcpResponseDTO{Data: r.Data})
 }
 
 // UnmarshalJSON satisfies the json.Unmarshaler interface.
 func (r *TCPResponse) UnmarshalJSON(b []byte) error {
+	return r.unmarshal(b, DecodeStrict)
+}
+
+func (r *TCPResponse) unmarshal(b []byte, _ DecodeMode) error {
 	var v tcpResponseDTO
 	err := json.Unmarshal(b, &v)
 	if err != nil {
@@ -239,6 +420,19 @@ func (p Predicate) MarshalJSON() ([]byte, error) {
 
 	// marshal request based on type
 	switch t := p.Request.(type) {
+	case nil:
+		return nil, errors.New("predicate request is required")
+
+	case *Predicate:
+		if t == nil {
+			return nil, errors.New("predicate request is required")
+		}
+		b, err := t.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		dto[p.Operator] = b
+
 	case json.Marshaler:
 		b, err := t.MarshalJSON()
 		if err != nil {
@@ -261,7 +455,7 @@ func (p Predicate) MarshalJSON() ([]byte, error) {
 		}
 		dto[p.Operator] = b
 
-	case string:
+	case string, map[string]bool:
 		b, err := json.Marshal(t)
 		if err != nil {
 			return nil, err
@@ -329,7 +523,7 @@ func (p *Predicate) UnmarshalJSON(b []byte) error {
 		switch key {
 		// Interpret the request as a string containing JavaScript if the
 		// inject operator is used.
-		case "inject":
+		case OpInject:
 			var js string
 			err = json.Unmarshal(b, &js)
 			if err != nil {
@@ -338,7 +532,7 @@ func (p *Predicate) UnmarshalJSON(b []byte) error {
 			p.Request = js
 
 		// Slice of predicates
-		case "and", "or":
+		case OpAnd, OpOr:
 			var ps []Predicate
 			err = json.Unmarshal(b, &ps)
 			if err != nil {
@@ -347,7 +541,7 @@ func (p *Predicate) UnmarshalJSON(b []byte) error {
 			p.Request = ps
 
 		// Single predicate
-		case "not":
+		case OpNot:
 			var v Predicate
 			err = json.Unmarshal(b, &v)
 			if err != nil {
@@ -368,6 +562,9 @@ const (
 	keyBehaviors = "_behaviors"
 )
 
+// responseTypes are the keys of a response which hold its value.
+var responseTypes = []string{"is", "proxy", "inject", "fault"}
+
 // MarshalJSON satisfies the json.Marshaler interface.
 func (r Response) MarshalJSON() ([]byte, error) {
 	dto := make(map[string]json.RawMessage)
@@ -392,6 +589,12 @@ func (r Response) MarshalJSON() ([]byte, error) {
 		dto[keyBehaviors] = behaviors
 	}
 
+	for key, v := range r.Extra {
+		if _, ok := dto[key]; !ok {
+			dto[key] = v
+		}
+	}
+
 	return json.Marshal(dto)
 }
 
@@ -415,22 +618,53 @@ func (r *Response) UnmarshalJSON(b []byte) error {
 		r.Behaviors = behaviors
 	}
 
-	for key, b := range dto {
-		r.Type = key
-		r.Value = b // defer unmarshaling until protocol is known
+	for _, key := range responseTypes {
+		if b, ok := dto[key]; ok {
+			r.Type = key
+			r.Value = b // defer unmarshaling until protocol is known
+			delete(dto, key)
+			break
+		}
+	}
+
+	if len(dto) > 0 {
+		r.Extra = dto
 	}
 
 	return nil
 }
 
 type stubDTO struct {
+	ID         string      `json:"id,omitempty"`
 	Predicates []Predicate `json:"predicates,omitempty"`
 	Responses  []Response  `json:"responses"`
+	Links      *linksDTO   `json:"_links,omitempty"`
+	Matches    []matchDTO  `json:"matches,omitempty"`
+}
+
+type matchDTO struct {
+	Timestamp string          `json:"timestamp"`
+	Request   json.RawMessage `json:"request"`
+	Response  json.RawMessage `json:"response"`
+}
+
+type linksDTO struct {
+	Self struct {
+		Href string `json:"href"`
+	} `json:"self"`
 }
 
 // MarshalJSON satisfies the json.Marshaler interface.
 func (s Stub) MarshalJSON() ([]byte, error) {
-	return json.Marshal(stubDTO(s))
+	b, err := json.Marshal(stubDTO{
+		ID:         s.ID,
+		Predicates: s.Predicates,
+		Responses:  s.Responses,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withExtraFields(b, s.Extra)
 }
 
 // UnmarshalJSON satisfies the json.Unmarshaler interface.
@@ -441,16 +675,34 @@ func (s *Stub) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
+	s.ID = dto.ID
 	s.Predicates = dto.Predicates
 	s.Responses = dto.Responses
+	if dto.Links != nil {
+		s.Self = dto.Links.Self.Href
+	}
+	if n := len(dto.Matches); n > 0 {
+		s.Matches = make([]Match, n)
+		for i, m := range dto.Matches {
+			// defer unmarshaling until protocol is known
+			s.Matches[i] = Match{Timestamp: m.Timestamp, Request: m.Request, Response: m.Response}
+		}
+	}
+	s.Extra, err = extraFields(b, "id", "predicates", "responses", "_links", "matches")
 
-	return nil
+	return err
+}
+
+type resolverDTO struct {
+	Inject string `json:"inject"`
 }
 
 type imposterRequestDTO struct {
 	Proto           string            `json:"protocol"`
 	Port            int               `json:"port,omitempty"`
 	Name            string            `json:"name,omitempty"`
+	Mode            string            `json:"mode,omitempty"`
+	Resolver        *resolverDTO      `json:"endOfRequestResolver,omitempty"`
 	RecordRequests  bool              `json:"recordRequests,omitempty"`
 	AllowCORS       bool              `json:"allowCORS,omitempty"`
 	DefaultResponse json.RawMessage   `json:"defaultResponse,omitempty"`
@@ -463,11 +715,15 @@ func (imp Imposter) MarshalJSON() ([]byte, error) {
 		Proto:           imp.Proto,
 		Port:            imp.Port,
 		Name:            imp.Name,
+		Mode:            imp.Mode,
 		RecordRequests:  imp.RecordRequests,
 		AllowCORS:       imp.AllowCORS,
 		DefaultResponse: nil,
 		Stubs:           nil,
 	}
+	if imp.EndOfRequestResolver != "" {
+		dto.Resolver = &resolverDTO{Inject: imp.EndOfRequestResolver}
+	}
 	if imp.DefaultResponse != nil {
 		jm, ok := imp.DefaultResponse.(json.Marshaler)
 		if !ok {
@@ -489,50 +745,72 @@ func (imp Imposter) MarshalJSON() ([]byte, error) {
 			dto.Stubs[i] = b
 		}
 	}
-	return json.Marshal(dto)
+	b, err := json.Marshal(dto)
+	if err != nil {
+		return nil, err
+	}
+	return withExtraFields(b, imp.Extra)
 }
 
 type imposterResponseDTO struct {
-	Port         int               `json:"port"`
-	Proto        string            `json:"protocol"`
-	Name         string            `json:"name,omitempty"`
-	RequestCount int               `json:"numberOfRequests,omitempty"`
-	Stubs        []json.RawMessage `json:"stubs,omitempty"`
-	Requests     []json.RawMessage `json:"requests,omitempty"`
+	Port            int               `json:"port"`
+	Proto           string            `json:"protocol"`
+	Name            string            `json:"name,omitempty"`
+	Mode            string            `json:"mode,omitempty"`
+	Resolver        *resolverDTO      `json:"endOfRequestResolver,omitempty"`
+	RecordRequests  bool              `json:"recordRequests,omitempty"`
+	AllowCORS       bool              `json:"allowCORS,omitempty"`
+	DefaultResponse json.RawMessage   `json:"defaultResponse,omitempty"`
+	RequestCount    int               `json:"numberOfRequests,omitempty"`
+	Stubs           []json.RawMessage `json:"stubs,omitempty"`
+	Requests        []json.RawMessage `json:"requests,omitempty"`
 }
 
-func getRequestUnmarshaler(proto string) (json.Unmarshaler, error) {
-	var um json.Unmarshaler
+// getRequestUnmarshaler returns the request type of the protocol proto, where
+// dataMode is the mode of the Imposter, which is recorded by TCP requests.
+func getRequestUnmarshaler(proto, dataMode string) (modeUnmarshaler, error) {
+	var um modeUnmarshaler
 	switch proto {
-	case "http":
+	case "http", "https":
 		um = &HTTPRequest{}
 	case "tcp":
-		um = &TCPRequest{}
+		um = &TCPRequest{Mode: dataMode}
 	default:
 		return nil, fmt.Errorf("unsupported protocol: %s", proto)
 	}
 	return um, nil
 }
 
-func unmarshalPredicateRecurse(proto string, p *Predicate) error {
+func unmarshalPredicateRecurse(proto, dataMode string, mode DecodeMode, p *Predicate) error {
 	switch v := p.Request.(type) {
 	case json.RawMessage:
-		um, err := getRequestUnmarshaler(proto)
+		if p.Operator == OpExists {
+			return unmarshalExistsRequest(proto, v, p)
+		}
+		um, err := getRequestUnmarshaler(proto, dataMode)
 		if err != nil {
 			return err
 		}
-		if err = um.UnmarshalJSON(v); err != nil {
+		if err = um.unmarshal(v, mode); err == nil && fitsRequest(um, v) {
+			p.Request = um
+			return nil
+		}
+		// fall back to the generic fields for the predicates which
+		// cannot be expressed by the request type of the protocol
+		var fs Fields
+		if err := fs.UnmarshalJSON(v); err != nil {
 			return err
 		}
-		p.Request = um
+		p.Request = fs
 
 	case Predicate:
-		if err := unmarshalPredicateRecurse(proto, &v); err != nil {
+		if err := unmarshalPredicateRecurse(proto, dataMode, mode, &v); err != nil {
 			return err
 		}
+		p.Request = v
 	case []Predicate:
 		for i := range v {
-			if err := unmarshalPredicateRecurse(proto, &v[i]); err != nil {
+			if err := unmarshalPredicateRecurse(proto, dataMode, mode, &v[i]); err != nil {
 				return err
 			}
 		}
@@ -540,21 +818,62 @@ func unmarshalPredicateRecurse(proto string, p *Predicate) error {
 	return nil
 }
 
-func getResponseUnmarshaler(proto string) (json.Unmarshaler, error) {
-	var um json.Unmarshaler
+// unmarshalExistsRequest decodes the request of an exists predicate, whose
+// fields are booleans rather than the values of a request. Like other
+// predicate requests, it is decoded as Fields if it cannot be expressed
+// exactly by the exists request type of the protocol, such as when testing
+// for the fields of a JSON body.
+func unmarshalExistsRequest(proto string, b json.RawMessage, p *Predicate) error {
+	var (
+		req interface{}
+		err error
+	)
+	switch proto {
+	case "http", "https":
+		r := &HTTPExistsRequest{}
+		err = r.UnmarshalJSON(b)
+		req = r
+	case "tcp":
+		var fields map[string]bool
+		err = json.Unmarshal(b, &fields)
+		req = fields
+	default:
+		return fmt.Errorf("unsupported protocol: %s", proto)
+	}
+	if err == nil && fitsRequest(req, b) {
+		p.Request = req
+		return nil
+	}
+
+	var fs Fields
+	if err := fs.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	p.Request = fs
+	return nil
+}
+
+// getResponseUnmarshaler returns the response type of the protocol proto, as
+// getRequestUnmarshaler does for requests.
+func getResponseUnmarshaler(proto, dataMode string) (modeUnmarshaler, error) {
+	var um modeUnmarshaler
 	switch proto {
-	case "http":
+	case "http", "https":
 		um = &HTTPResponse{}
 	case "tcp":
-		um = &TCPResponse{}
+		um = &TCPResponse{Mode: dataMode}
 	default:
 		return nil, fmt.Errorf("unsupported protocol: %s", proto)
 	}
 	return um, nil
 }
 
-// UnmarshalJSON satisfies the json.Unmarshaler interface.
+// UnmarshalJSON satisfies the json.Unmarshaler interface, using DecodeStrict.
 func (imp *Imposter) UnmarshalJSON(b []byte) error {
+	return imp.unmarshal(b, DecodeStrict)
+}
+
+func (imp *Imposter) unmarshal(b []byte, mode DecodeMode) error {
 	var dto imposterResponseDTO
 	err := json.Unmarshal(b, &dto)
 	if err != nil {
@@ -564,7 +883,30 @@ func (imp *Imposter) UnmarshalJSON(b []byte) error {
 	imp.Port = dto.Port
 	imp.Proto = dto.Proto
 	imp.Name = dto.Name
+	imp.Mode = dto.Mode
+	if dto.Resolver != nil {
+		imp.EndOfRequestResolver = dto.Resolver.Inject
+	}
+	imp.RecordRequests = dto.RecordRequests
+	imp.AllowCORS = dto.AllowCORS
 	imp.RequestCount = dto.RequestCount
+	imp.Extra, err = extraFields(b, "port", "protocol", "name", "mode", "endOfRequestResolver", "recordRequests", "allowCORS",
+		"defaultResponse", "numberOfRequests", "stubs", "requests")
+	if err != nil {
+		return err
+	}
+
+	if len(dto.DefaultResponse) > 0 {
+		um, err := getResponseUnmarshaler(imp.Proto, imp.Mode)
+		if err != nil {
+			return err
+		}
+		err = um.unmarshal(dto.DefaultResponse, mode)
+		if err != nil {
+			return err
+		}
+		imp.DefaultResponse = um
+	}
 
 	if n := len(dto.Stubs); n > 0 {
 		imp.Stubs = make([]Stub, n)
@@ -576,19 +918,22 @@ func (imp *Imposter) UnmarshalJSON(b []byte) error {
 			}
 
 			for i := range s.Predicates {
-				err = unmarshalPredicateRecurse(imp.Proto, &s.Predicates[i])
+				err = unmarshalPredicateRecurse(imp.Proto, imp.Mode, mode, &s.Predicates[i])
 				if err != nil {
 					return err
 				}
 			}
 
 			for i, r := range s.Responses {
-				if raw, ok := r.Value.(json.RawMessage); ok {
-					um, err := getResponseUnmarshaler(imp.Proto)
+				// only "is" responses are of the protocol response type;
+				// the values of proxy, inject and fault responses are kept
+				// as raw JSON
+				if raw, ok := r.Value.(json.RawMessage); ok && r.Type == "is" {
+					um, err := getResponseUnmarshaler(imp.Proto, imp.Mode)
 					if err != nil {
 						return err
 					}
-					err = um.UnmarshalJSON(raw)
+					err = um.unmarshal(raw, mode)
 					if err != nil {
 						return err
 					}
@@ -596,6 +941,31 @@ func (imp *Imposter) UnmarshalJSON(b []byte) error {
 				}
 			}
 
+			for i, m := range s.Matches {
+				if raw, ok := m.Request.(json.RawMessage); ok {
+					um, err := getRequestUnmarshaler(imp.Proto, imp.Mode)
+					if err != nil {
+						return err
+					}
+					err = um.unmarshal(raw, mode)
+					if err != nil {
+						return err
+					}
+					s.Matches[i].Request = um
+				}
+				if raw, ok := m.Response.(json.RawMessage); ok {
+					um, err := getResponseUnmarshaler(imp.Proto, imp.Mode)
+					if err != nil {
+						return err
+					}
+					err = um.unmarshal(raw, mode)
+					if err != nil {
+						return err
+					}
+					s.Matches[i].Response = um
+				}
+			}
+
 			imp.Stubs[i] = s
 		}
 	}
@@ -603,11 +973,11 @@ func (imp *Imposter) UnmarshalJSON(b []byte) error {
 	if n := len(dto.Requests); n > 0 {
 		imp.Requests = make([]interface{}, n)
 		for i, b := range dto.Requests {
-			um, err := getRequestUnmarshaler(imp.Proto)
+			um, err := getRequestUnmarshaler(imp.Proto, imp.Mode)
 			if err != nil {
 				return err
 			}
-			err = um.UnmarshalJSON(b)
+			err = um.unmarshal(b, mode)
 			if err != nil {
 				return err
 			}
@@ -617,3 +987,14 @@ func (imp *Imposter) UnmarshalJSON(b []byte) error {
 
 	return nil
 }
+
+// modalImposter decodes an Imposter using the given DecodeMode.
+type modalImposter struct {
+	imp  *Imposter
+	mode DecodeMode
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+func (m modalImposter) UnmarshalJSON(b []byte) error {
+	return m.imp.unmarshal(b, m.mode)
+}
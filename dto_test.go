@@ -4,6 +4,7 @@
 package mbgo_test
 
 import (
+	"context"
 	"encoding/json"
 	"net"
 	"net/http"
@@ -632,6 +633,11 @@ func TestImposter_UnmarshalJSON(t *testing.T) {
 				"protocol":         "http",
 				"name":             "http_imposter",
 				"numberOfRequests": 42,
+				"recordRequests":   true,
+				"allowCORS":        true,
+				"defaultResponse": map[string]interface{}{
+					"statusCode": 404,
+				},
 				"stubs": []interface{}{
 					map[string]interface{}{
 						"predicates": []interface{}{
@@ -670,10 +676,15 @@ func TestImposter_UnmarshalJSON(t *testing.T) {
 				},
 			},
 			Expected: mbgo.Imposter{
-				Port:         8080,
-				Proto:        "http",
-				Name:         "http_imposter",
-				RequestCount: 42,
+				Port:           8080,
+				Proto:          "http",
+				Name:           "http_imposter",
+				RequestCount:   42,
+				RecordRequests: true,
+				AllowCORS:      true,
+				DefaultResponse: &mbgo.HTTPResponse{
+					StatusCode: http.StatusNotFound,
+				},
 				Stubs: []mbgo.Stub{
 					{
 						Predicates: []mbgo.Predicate{
@@ -681,6 +692,7 @@ func TestImposter_UnmarshalJSON(t *testing.T) {
 								Operator: "equals",
 								Request: &mbgo.HTTPRequest{
 									RequestFrom: net.IPv4(172, 17, 0, 1),
+									RemoteAddr:  &net.TCPAddr{IP: net.IPv4(172, 17, 0, 1), Port: 58112},
 									Method:      "POST",
 									Path:        "/foo",
 									Query: map[string][]string{
@@ -714,6 +726,64 @@ func TestImposter_UnmarshalJSON(t *testing.T) {
 				},
 			},
 		},
+		{
+			Description: "should unmarshal the JSON into the expected https Imposter",
+			JSON: map[string]interface{}{
+				"port":     8443,
+				"protocol": "https",
+				"defaultResponse": map[string]interface{}{
+					"statusCode": 404,
+				},
+				"stubs": []interface{}{
+					map[string]interface{}{
+						"predicates": []interface{}{
+							map[string]interface{}{
+								"equals": map[string]interface{}{
+									"path": "/foo",
+								},
+							},
+						},
+						"responses": []interface{}{
+							map[string]interface{}{
+								"is": map[string]interface{}{
+									"statusCode": 200,
+								},
+							},
+						},
+					},
+				},
+				"requests": []interface{}{
+					map[string]interface{}{
+						"method": "GET",
+						"path":   "/foo",
+					},
+				},
+			},
+			Expected: mbgo.Imposter{
+				Port:            8443,
+				Proto:           "https",
+				DefaultResponse: &mbgo.HTTPResponse{StatusCode: http.StatusNotFound},
+				Stubs: []mbgo.Stub{
+					{
+						Predicates: []mbgo.Predicate{
+							{
+								Operator: "equals",
+								Request:  &mbgo.HTTPRequest{Path: "/foo"},
+							},
+						},
+						Responses: []mbgo.Response{
+							{
+								Type:  "is",
+								Value: &mbgo.HTTPResponse{StatusCode: http.StatusOK},
+							},
+						},
+					},
+				},
+				Requests: []interface{}{
+					&mbgo.HTTPRequest{Method: http.MethodGet, Path: "/foo"},
+				},
+			},
+		},
 		{
 			Description: "should unmarshal the JSON into the expected tcp Imposter",
 			JSON: map[string]interface{}{
@@ -753,6 +823,7 @@ func TestImposter_UnmarshalJSON(t *testing.T) {
 								Operator: "equals",
 								Request: &mbgo.TCPRequest{
 									RequestFrom: net.IPv4(172, 17, 0, 1),
+									RemoteAddr:  &net.TCPAddr{IP: net.IPv4(172, 17, 0, 1), Port: 58112},
 									Data:        "SGVsbG8sIHdvcmxkIQ==",
 								},
 							},
@@ -787,3 +858,213 @@ func TestImposter_UnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestImposter_UnmarshalJSON_Extra(t *testing.T) {
+	in := `{
+		"protocol": "http",
+		"port": 8080,
+		"recordRequests": true,
+		"_links": {"self": {"href": "http://localhost:2525/imposters/8080"}},
+		"stubs": [
+			{
+				"predicates": [{"equals": {"path": "/foo", "ip": "::ffff:172.17.0.1"}}],
+				"responses": [
+					{
+						"is": {"statusCode": 200, "_proxyResponseTime": 12},
+						"repeat": 2
+					},
+					{"inject": "function (config) { return {statusCode: 201}; }"},
+					{"proxy": {"to": "http://localhost:8081", "mode": "proxyOnce"}}
+				],
+				"scenarioName": "login"
+			}
+		]
+	}`
+
+	var imp mbgo.Imposter
+	assert.MustOk(t, json.Unmarshal([]byte(in), &imp))
+
+	assert.Equals(t, map[string]json.RawMessage{
+		"_links": json.RawMessage(`{"self": {"href": "http://localhost:2525/imposters/8080"}}`),
+	}, imp.Extra)
+	assert.Equals(t, map[string]json.RawMessage{
+		"scenarioName": json.RawMessage(`"login"`),
+	}, imp.Stubs[0].Extra)
+	assert.Equals(t, map[string]json.RawMessage{
+		"ip": json.RawMessage(`"::ffff:172.17.0.1"`),
+	}, imp.Stubs[0].Predicates[0].Request.(*mbgo.HTTPRequest).Extra)
+	assert.Equals(t, "is", imp.Stubs[0].Responses[0].Type)
+	assert.Equals(t, map[string]json.RawMessage{
+		"repeat": json.RawMessage(`2`),
+	}, imp.Stubs[0].Responses[0].Extra)
+	assert.Equals(t, map[string]json.RawMessage{
+		"_proxyResponseTime": json.RawMessage(`12`),
+	}, imp.Stubs[0].Responses[0].Value.(*mbgo.HTTPResponse).Extra)
+	assert.Equals(t, mbgo.Response{
+		Type:  "inject",
+		Value: json.RawMessage(`"function (config) { return {statusCode: 201}; }"`),
+	}, imp.Stubs[0].Responses[1])
+	assert.Equals(t, mbgo.Response{
+		Type:  "proxy",
+		Value: json.RawMessage(`{"to": "http://localhost:8081", "mode": "proxyOnce"}`),
+	}, imp.Stubs[0].Responses[2])
+
+	out, err := json.Marshal(imp)
+	assert.MustOk(t, err)
+
+	var want, got interface{}
+	assert.MustOk(t, json.Unmarshal([]byte(in), &want))
+	assert.MustOk(t, json.Unmarshal(out, &got))
+	assert.Equals(t, want, got)
+}
+
+func TestStub_JSON_Identity(t *testing.T) {
+	in := `{
+		"id": "login",
+		"responses": [{"is": {"statusCode": 200}}],
+		"_links": {"self": {"href": "http://localhost:2525/imposters/8080/stubs/1"}}
+	}`
+
+	var stub mbgo.Stub
+	assert.MustOk(t, json.Unmarshal([]byte(in), &stub))
+	assert.Equals(t, "login", stub.ID)
+	assert.Equals(t, "http://localhost:2525/imposters/8080/stubs/1", stub.Self)
+	assert.Equals(t, map[string]json.RawMessage(nil), stub.Extra)
+
+	b, err := json.Marshal(stub)
+	assert.MustOk(t, err)
+	assert.Equals(t, `{"id":"login","responses":[{"is":{"statusCode":200}}]}`, string(b))
+}
+
+func TestImposter_JSON_BinaryMode(t *testing.T) {
+	modbus := []byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x06, 0x11, 0x03}
+	imp := mbgo.Imposter{
+		Port:  502,
+		Proto: "tcp",
+		Mode:  mbgo.ModeBinary,
+		Stubs: []mbgo.Stub{
+			{
+				Predicates: []mbgo.Predicate{
+					{Operator: "startsWith", Request: mbgo.NewTCPRequest(mbgo.ModeBinary, modbus[:2])},
+				},
+				Responses: []mbgo.Response{
+					{Type: "is", Value: mbgo.NewTCPResponse(mbgo.ModeBinary, modbus)},
+				},
+			},
+		},
+	}
+
+	b, err := json.Marshal(imp)
+	assert.MustOk(t, err)
+	assert.Equals(t, `{"protocol":"tcp","port":502,"mode":"binary","stubs":[{"predicates":[{"startsWith":{"data":"AAE="}}],"responses":[{"is":{"data":"AAEAAAAGEQM="}}]}]}`, string(b))
+
+	var got mbgo.Imposter
+	assert.MustOk(t, json.Unmarshal(b, &got))
+	assert.Equals(t, mbgo.ModeBinary, got.Mode)
+
+	data, err := got.Stubs[0].Responses[0].Value.(*mbgo.TCPResponse).Bytes()
+	assert.MustOk(t, err)
+	assert.Equals(t, modbus, data)
+
+	req := got.Stubs[0].Predicates[0].Request.(*mbgo.TCPRequest)
+	assert.Equals(t, mbgo.ModeBinary, req.Mode)
+	data, err = req.Bytes()
+	assert.MustOk(t, err)
+	assert.Equals(t, modbus[:2], data)
+
+	data, err = mbgo.TCPRequest{Data: "hello"}.Bytes()
+	assert.MustOk(t, err)
+	assert.Equals(t, []byte("hello"), data)
+}
+
+func TestHTTPRequest_JSON_MappedRequestFrom(t *testing.T) {
+	in := `{"protocol":"http","port":8080,"stubs":[{"predicates":[{"equals":{"requestFrom":"::ffff:172.17.0.1:56912"}}],` +
+		`"responses":[{"is":{"statusCode":200}}]}],` +
+		`"requests":[{"requestFrom":"::ffff:172.17.0.1:56912","method":"GET"}]}`
+
+	var imp mbgo.Imposter
+	assert.MustOk(t, json.Unmarshal([]byte(in), &imp))
+
+	pred, ok := imp.Stubs[0].Predicates[0].Request.(*mbgo.HTTPRequest)
+	assert.Equals(t, true, ok)
+	assert.Equals(t, net.IPv4(172, 17, 0, 1), pred.RequestFrom)
+	assert.Equals(t, "::ffff:172.17.0.1:56912", pred.RemoteAddr.String())
+
+	b, err := json.Marshal(imp.Stubs[0])
+	assert.MustOk(t, err)
+	assert.Equals(t, `{"predicates":[{"equals":{"requestFrom":"::ffff:172.17.0.1:56912"}}],"responses":[{"is":{"statusCode":200}}]}`, string(b))
+
+	b, err = json.Marshal(imp.Requests[0])
+	assert.MustOk(t, err)
+	assert.Equals(t, `{"requestFrom":"::ffff:172.17.0.1:56912","method":"GET"}`, string(b))
+}
+
+func TestHTTPRequest_UnmarshalJSON_Strict(t *testing.T) {
+	cases := []struct {
+		Description string
+		JSON        string
+		Err         string
+	}{
+		{
+			Description: "should report a malformed requestFrom",
+			JSON:        `{"requestFrom":"not an address:1"}`,
+			Err:         "invalid requestFrom: invalid IP address: not an address",
+		},
+		{
+			Description: "should report a requestFrom without a port",
+			JSON:        `{"requestFrom":"172.17.0.1:"}`,
+			Err:         "invalid requestFrom: invalid port: ",
+		},
+		{
+			Description: "should report non-string header values",
+			JSON:        `{"headers":{"Content-Length":42}}`,
+			Err:         "invalid headers: invalid value type for key \"Content-Length\": 42",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.Description, func(t *testing.T) {
+			t.Parallel()
+
+			var r mbgo.HTTPRequest
+			err := json.Unmarshal([]byte(c.JSON), &r)
+			assert.Equals(t, c.Err, err.Error())
+		})
+	}
+}
+
+func TestClient_WithDecodeMode(t *testing.T) {
+	body := `{"port":8080,"protocol":"http","requests":[
+		{"requestFrom":"bogus","method":"GET","headers":{"Content-Length":42}}
+	]}`
+
+	t.Run("should fail by default", func(t *testing.T) {
+		var reqs []*http.Request
+		mb := mbgo.NewClient(http.DefaultClient, nil, mbgo.WithMiddleware(respondWith(&reqs, http.StatusOK, body)))
+
+		_, err := mb.Imposter(context.Background(), 8080, false)
+		assert.Equals(t, "invalid requestFrom: missing port in address: bogus", err.Error())
+	})
+
+	t.Run("should keep the raw values when lenient", func(t *testing.T) {
+		var reqs []*http.Request
+		mb := mbgo.NewClient(http.DefaultClient, nil,
+			mbgo.WithDecodeMode(mbgo.DecodeLenient),
+			mbgo.WithMiddleware(respondWith(&reqs, http.StatusOK, `{"imposters":[`+body+`]}`)),
+		)
+
+		imps, err := mb.Imposters(context.Background(), false)
+		assert.MustOk(t, err)
+		assert.Equals(t, []interface{}{
+			&mbgo.HTTPRequest{
+				Method: http.MethodGet,
+				Extra: map[string]json.RawMessage{
+					"requestFrom": json.RawMessage(`"bogus"`),
+					"headers":     json.RawMessage(`{"Content-Length":42}`),
+				},
+			},
+		}, imps[0].Requests)
+	})
+}
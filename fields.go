@@ -0,0 +1,112 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// Field is a single named value of a Fields predicate request.
+type Field struct {
+	Name  string
+	Value interface{}
+}
+
+// Fields is a predicate request given as an ordered list of fields, for the
+// requests which cannot be expressed exactly by HTTPRequest or TCPRequest,
+// such as a field which is present but empty. Unlike the structured request
+// types, every Field is marshalled, including zero values.
+type Fields []Field
+
+// Get returns the value of the first Field with the given name, and whether
+// it was found.
+func (fs Fields) Get(name string) (interface{}, bool) {
+	for _, f := range fs {
+		if f.Name == name {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Set returns fs with the value of the first Field with the given name
+// replaced by v, or with a new Field appended if there is none.
+func (fs Fields) Set(name string, v interface{}) Fields {
+	for i := range fs {
+		if fs[i].Name == name {
+			fs[i].Value = v
+			return fs
+		}
+	}
+	return append(fs, Field{Name: name, Value: v})
+}
+
+// MarshalJSON satisfies the json.Marshaler interface, writing the fields as
+// a JSON object in order.
+func (fs Fields) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range fs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface, keeping the order
+// of the fields of a JSON object. The values are decoded as by json.Unmarshal
+// into an interface{}.
+func (fs *Fields) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return errors.New("fields must be a JSON object")
+	}
+
+	out := Fields{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		out = append(out, Field{Name: tok.(string), Value: v})
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	*fs = out
+	return nil
+}
+
+// fitsRequest reports whether the decoded request r marshals back into the
+// JSON b it was decoded from, meaning that no field was lost or altered.
+func fitsRequest(r interface{}, b []byte) bool {
+	var want interface{}
+	if err := json.Unmarshal(b, &want); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(want, normaliseValue(r))
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+)
+
+func TestFields_JSON(t *testing.T) {
+	fs := mbgo.Fields{
+		{Name: "path", Value: "/foo"},
+		{Name: "method", Value: ""},
+		{Name: "body", Value: map[string]interface{}{"nested": map[string]interface{}{"a": 1}}},
+	}
+	fs = fs.Set("method", http.MethodGet).Set("headers", map[string]string{"X": ""})
+
+	b, err := json.Marshal(fs)
+	assert.MustOk(t, err)
+	assert.Equals(t, `{"path":"/foo","method":"GET","body":{"nested":{"a":1}},"headers":{"X":""}}`, string(b))
+
+	var got mbgo.Fields
+	assert.MustOk(t, json.Unmarshal(b, &got))
+	assert.Equals(t, 4, len(got))
+	assert.Equals(t, "headers", got[3].Name)
+	v, ok := got.Get("body")
+	assert.Equals(t, true, ok)
+	assert.Equals(t, map[string]interface{}{"nested": map[string]interface{}{"a": float64(1)}}, v)
+	_, ok = got.Get("query")
+	assert.Equals(t, false, ok)
+
+	assert.Equals(t, true, json.Unmarshal([]byte(`["path"]`), &got) != nil)
+}
+
+func TestPredicate_UnmarshalJSON_Fields(t *testing.T) {
+	cases := []struct {
+		Description string
+		Request     string
+		Expected    interface{}
+	}{
+		{
+			Description: "should decode an HTTPRequest if it round-trips",
+			Request:     `{"method":"GET","headers":{"X":"y"},"body":{"nested":{"a":1}}}`,
+			Expected: &mbgo.HTTPRequest{
+				Method:  http.MethodGet,
+				Headers: map[string][]string{"X": {"y"}},
+				Body:    map[string]interface{}{"nested": map[string]interface{}{"a": float64(1)}},
+			},
+		},
+		{
+			Description: "should decode Fields if an empty field would be dropped",
+			Request:     `{"path":"","headers":{"X":"y"}}`,
+			Expected: mbgo.Fields{
+				{Name: "path", Value: ""},
+				{Name: "headers", Value: map[string]interface{}{"X": "y"}},
+			},
+		},
+		{
+			Description: "should decode Fields if a value has an unsupported type",
+			Request:     `{"query":{"q":1}}`,
+			Expected:    mbgo.Fields{{Name: "query", Value: map[string]interface{}{"q": float64(1)}}},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.Description, func(t *testing.T) {
+			t.Parallel()
+
+			b := `{"protocol":"http","port":8080,"stubs":[{"predicates":[{"equals":` + c.Request + `}]}]}`
+			var imp mbgo.Imposter
+			assert.MustOk(t, json.Unmarshal([]byte(b), &imp))
+			assert.Equals(t, c.Expected, imp.Stubs[0].Predicates[0].Request)
+
+			out, err := json.Marshal(imp.Stubs[0].Predicates[0])
+			assert.MustOk(t, err)
+			assert.Equals(t, `{"equals":`+c.Request+`}`, string(out))
+		})
+	}
+}
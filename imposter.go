@@ -4,6 +4,8 @@
 package mbgo
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"net"
 	"net/http"
 	"net/url"
@@ -18,6 +20,13 @@ type HTTPRequest struct {
 	// RequestFrom is the originating address of the incoming request.
 	RequestFrom net.IP
 
+	// RemoteAddr is the originating address of the incoming request,
+	// including its port, which takes precedence over RequestFrom when
+	// encoding the request. It is a *net.TCPAddr when decoded, unless
+	// mountebank reports an IPv4 address in the IPv4-mapped IPv6 form, e.g.
+	// "::ffff:172.17.0.1:56912", which is then kept when encoding it again.
+	RemoteAddr net.Addr
+
 	// Method is the HTTP request method.
 	Method string
 
@@ -27,6 +36,10 @@ type HTTPRequest struct {
 	// Query contains the URL query parameters of the request.
 	Query url.Values
 
+	// Form contains the fields of a request with an url-encoded body,
+	// as parsed by mountebank.
+	Form url.Values
+
 	// Headers contains the HTTP headers of the request.
 	Headers http.Header
 
@@ -35,8 +48,43 @@ type HTTPRequest struct {
 
 	// Timestamp is the timestamp of the request.
 	Timestamp string
+
+	// Extra contains any fields of the request not modelled above, keyed by
+	// their JSON name. They are kept when decoding so that a request read from
+	// mountebank can be written back to it unchanged.
+	Extra map[string]json.RawMessage
 }
 
+// HTTPExistsRequest is the request of an "exists" Predicate on an Imposter
+// of the "http" protocol, which matches requests in which each of its set
+// fields exists if true or does not exist if false.
+//
+// See more information about the exists predicate at:
+// http://www.mbtest.org/docs/api/predicates.
+type HTTPExistsRequest struct {
+	// Method, Path and Body test the existence of the request method,
+	// path and body. Use a JSONPath, or Fields with a nested body, to test
+	// for the fields of a JSON body.
+	Method *bool
+	Path   *bool
+	Body   *bool
+
+	// Query, Form and Headers test the existence of the given query
+	// parameters, form fields and headers.
+	Query   map[string]bool
+	Form    map[string]bool
+	Headers map[string]bool
+}
+
+// The modes of a "tcp" Imposter, and of an HTTPResponse.
+const (
+	// ModeText uses the data as plaintext, which is the default.
+	ModeText = "text"
+
+	// ModeBinary uses the data as base64 encoded binary.
+	ModeBinary = "binary"
+)
+
 // TCPRequest describes incoming TCP data received by an Imposter of
 // the "tcp" protocol.
 //
@@ -46,8 +94,32 @@ type TCPRequest struct {
 	// RequestFrom is the originating address of the incoming request.
 	RequestFrom net.IP
 
-	// Data is the data in the request as plaintext.
+	// RemoteAddr is the originating address of the incoming request,
+	// including its port, which takes precedence over RequestFrom when
+	// encoding the request. It is a *net.TCPAddr when decoded, unless
+	// mountebank reports an IPv4 address in the IPv4-mapped IPv6 form, e.g.
+	// "::ffff:172.17.0.1:56912", which is then kept when encoding it again.
+	RemoteAddr net.Addr
+
+	// Data is the data in the request as plaintext, or base64 encoded if
+	// the Imposter uses ModeBinary; see Bytes and NewTCPRequest.
 	Data string
+
+	// Mode is the mode of the Imposter the Data is used by, which is set
+	// by NewTCPRequest and when decoding an Imposter. It is not encoded.
+	Mode string
+}
+
+// NewTCPRequest returns a TCPRequest whose Data is data as used by an
+// Imposter of the given mode, for instance in a Predicate.
+func NewTCPRequest(mode string, data []byte) TCPRequest {
+	return TCPRequest{Data: encodeData(mode, data), Mode: mode}
+}
+
+// Bytes returns the data in the request, decoding it from base64 if its
+// Mode is ModeBinary.
+func (r TCPRequest) Bytes() ([]byte, error) {
+	return decodeData(r.Mode, r.Data)
 }
 
 // JSONPath is a predicate parameter used to narrow the scope of a tested value
@@ -70,7 +142,13 @@ type Predicate struct {
 	Operator string
 
 	// Request is the request value challenged against the Operator;
-	// either of type HTTPRequest or TCPRequest.
+	// either of type HTTPRequest, TCPRequest or Fields, or a Predicate for
+	// the "not" operator, a []Predicate for "and" and "or", a JavaScript
+	// string for "inject" and an HTTPExistsRequest or a map[string]bool for
+	// "exists". See Equals, Not, etc.
+	//
+	// A request decoded from JSON is of type Fields if it cannot be
+	// expressed exactly by the request type of the protocol.
 	Request interface{}
 
 	// JSONPath is the predicate parameter for narrowing the scope of JSON
@@ -98,6 +176,10 @@ type HTTPResponse struct {
 	// Mode is the mode of the response; either "text" or "binary".
 	// Defaults to "text" if excluded.
 	Mode string
+
+	// Extra contains any fields of the response not modelled above, such
+	// as those added by newer versions of mountebank, keyed by JSON name.
+	Extra map[string]json.RawMessage
 }
 
 // TCPResponse is a Response.Value to a matched incoming TCPRequest.
@@ -107,8 +189,39 @@ type HTTPResponse struct {
 type TCPResponse struct {
 	// Data is the data in the data contained in the response.
 	// An empty string does not respond with data, but does send
-	// the FIN bit.
+	// the FIN bit. It is base64 encoded if the Imposter uses ModeBinary;
+	// see Bytes and NewTCPResponse.
 	Data string
+
+	// Mode is the mode of the Imposter sending the response, which is set
+	// by NewTCPResponse and when decoding an Imposter. It is not encoded.
+	Mode string
+}
+
+// NewTCPResponse returns a TCPResponse sending data from an Imposter of
+// the given mode.
+func NewTCPResponse(mode string, data []byte) TCPResponse {
+	return TCPResponse{Data: encodeData(mode, data), Mode: mode}
+}
+
+// Bytes returns the data sent by the response, decoding it from base64 if
+// its Mode is ModeBinary.
+func (r TCPResponse) Bytes() ([]byte, error) {
+	return decodeData(r.Mode, r.Data)
+}
+
+func encodeData(mode string, data []byte) string {
+	if mode == ModeBinary {
+		return base64.StdEncoding.EncodeToString(data)
+	}
+	return string(data)
+}
+
+func decodeData(mode, data string) ([]byte, error) {
+	if mode == ModeBinary {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	return []byte(data), nil
 }
 
 // Behaviors defines the possible response behaviors for a stub.
@@ -134,11 +247,33 @@ type Response struct {
 	// Type is the type of the Response; one of "is", "proxy" or "inject".
 	Type string
 
-	// Value is the value of the Response; either of type HTTPResponse or TCPResponse.
+	// Value is the value of the Response; either of type HTTPResponse or
+	// TCPResponse for "is" responses. The values of other responses must
+	// implement json.Marshaler, and are decoded as a json.RawMessage, such
+	// as the JSON string holding the JavaScript of an "inject" response.
 	Value interface{}
 
 	// Behaviors is an optional field allowing the user to define response behavior.
 	Behaviors *Behaviors
+
+	// Extra contains any fields of the Response other than its type and
+	// behaviors, keyed by JSON name.
+	Extra map[string]json.RawMessage
+}
+
+// Match describes an incoming request matched by a Stub, as recorded by
+// mountebank when started using the --debug option.
+type Match struct {
+	// Timestamp is the time the request was matched.
+	Timestamp string
+
+	// Request is the matched request; either of type *HTTPRequest or
+	// *TCPRequest depending on the protocol of the Imposter.
+	Request interface{}
+
+	// Response is the response sent to the request; either of type
+	// *HTTPResponse or *TCPResponse depending on the protocol of the Imposter.
+	Response interface{}
 }
 
 // Stub adds behaviour to Imposters where one or more registered Responses
@@ -152,6 +287,11 @@ type Response struct {
 // See more information about stubs in mountebank at:
 // http://www.mbtest.org/docs/api/stubs.
 type Stub struct {
+	// ID optionally identifies the Stub, allowing it to be changed using
+	// Client.OverwriteStubByID and Client.RemoveStubByID regardless of its
+	// current index. It is stored by mountebank along with the Stub.
+	ID string
+
 	// Predicates are the list of Predicates associated with the Stub,
 	// which are logically AND'd together if more than one exists.
 	Predicates []Predicate
@@ -159,6 +299,19 @@ type Stub struct {
 	// Responses are the circular queue of Responses used to respond to
 	// incoming matched requests.
 	Responses []Response
+
+	// Self is the URL of the Stub given by mountebank in its _links, which
+	// is only set when the Stub is retrieved and never sent back.
+	Self string
+
+	// Matches are the requests matched by the Stub, which are only recorded
+	// when mountebank is started using the --debug option and are never
+	// sent back.
+	Matches []Match
+
+	// Extra contains any fields of the Stub not modelled above, keyed by
+	// JSON name.
+	Extra map[string]json.RawMessage
 }
 
 // Imposter is the primary mountebank resource, representing a server/service
@@ -179,6 +332,18 @@ type Imposter struct {
 	// Proto is the listening protocol of the Imposter; required.
 	Proto string
 
+	// Mode is the mode of an Imposter of the "tcp" protocol; either ModeText
+	// or ModeBinary, in which case the Data of its TCPRequest and TCPResponse
+	// values is base64 encoded. Defaults to ModeText if excluded.
+	Mode string
+
+	// EndOfRequestResolver is the JavaScript function used by an Imposter of
+	// the "tcp" protocol to determine whether the data received so far is a
+	// complete request, for protocols whose requests span multiple packets.
+	// See FixedLengthResolver, LengthPrefixResolver and DelimiterResolver
+	// for common framings.
+	EndOfRequestResolver string
+
 	// Name is the name of the Imposter.
 	Name string
 
@@ -206,4 +371,9 @@ type Imposter struct {
 
 	// Stubs contains zero or more valid Stubs associated with the Imposter.
 	Stubs []Stub
+
+	// Extra contains any fields of the Imposter not modelled above, keyed by
+	// JSON name, so that reading an Imposter and writing it back to mountebank
+	// does not lose any of its configuration.
+	Extra map[string]json.RawMessage
 }
@@ -8,24 +8,61 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 // Client represents a generic HTTP REST client that handles
 // a JSON structure in requests and responses.
 type Client struct {
-	baseURL    *url.URL
-	httpClient *http.Client
+	baseURL     *url.URL
+	httpClient  *http.Client
+	retry       retryPolicy
+	hooks       []func(*http.Request) error
+	middlewares []Middleware
+}
+
+// Option configures optional behaviour of a Client.
+type Option func(*Client)
+
+// DoFunc sends an HTTP request and returns an HTTP response.
+type DoFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wraps a DoFunc with additional behaviour.
+type Middleware func(next DoFunc) DoFunc
+
+// WithRequestHook adds a hook called by NewRequest on every request it
+// builds, in the order the hooks were added; an error returned by a hook
+// is returned by NewRequest.
+func WithRequestHook(hook func(*http.Request) error) Option {
+	return func(cli *Client) {
+		cli.hooks = append(cli.hooks, hook)
+	}
+}
+
+// WithMiddleware wraps Do with the given Middleware, where the first
+// Middleware added is the outermost one. Each Middleware is called once
+// per call to Do, regardless of any retries.
+func WithMiddleware(mw Middleware) Option {
+	return func(cli *Client) {
+		cli.middlewares = append(cli.middlewares, mw)
+	}
 }
 
 // NewClient returns a new instance of *Client from the provided
-// inner *http.Client httpClient and API base *url.URL baseURL.
-func NewClient(cli *http.Client, root *url.URL) *Client {
-	return &Client{
+// inner *http.Client httpClient and API base *url.URL baseURL,
+// along with any optional Option values.
+func NewClient(cli *http.Client, root *url.URL, opts ...Option) *Client {
+	c := &Client{
 		httpClient: cli,
 		baseURL:    root,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // NewRequest builds the specified *http.Request value from the
@@ -47,12 +84,60 @@ func (cli *Client) NewRequest(ctx context.Context, method, path string, body io.
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	return req.WithContext(ctx), nil
+	req = req.WithContext(ctx)
+	for _, hook := range cli.hooks {
+		if err := hook(req); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
 }
 
-// Do sends an HTTP request and returns an HTTP response.
+// Do sends an HTTP request and returns an HTTP response, retrying
+// idempotent requests according to the retry policy set using WithRetry,
+// through any Middleware set using WithMiddleware.
 func (cli *Client) Do(req *http.Request) (*http.Response, error) {
-	return cli.httpClient.Do(req)
+	do := DoFunc(cli.do)
+	for i := len(cli.middlewares) - 1; i >= 0; i-- {
+		do = cli.middlewares[i](do)
+	}
+	return do(req)
+}
+
+func (cli *Client) do(req *http.Request) (*http.Response, error) {
+	if cli.retry.maxRetries <= 0 || !idempotent(req) {
+		return cli.httpClient.Do(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := cli.httpClient.Do(req)
+		if attempt >= cli.retry.maxRetries || !retryable(req.Context(), resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			// drain the body so the underlying connection can be reused
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(cli.retry.backoff(attempt))
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
 }
 
 // DecodeResponseBody reads a JSON-encoded value from the provided
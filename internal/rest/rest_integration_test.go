@@ -8,9 +8,12 @@ package rest_test
 
 import (
 	"context"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -47,3 +50,91 @@ func TestClient_Do_Integration(t *testing.T) {
 		assert.Equals(t, context.DeadlineExceeded, urlErr.Err)
 	})
 }
+
+func TestClient_Do_Retry_Integration(t *testing.T) {
+	newServer := func(failures int, calls *int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			if int(atomic.AddInt32(calls, 1)) <= failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(b)
+		}))
+	}
+
+	t.Run("should retry idempotent requests with their body until they succeed", func(t *testing.T) {
+		var calls int32
+		srv := newServer(2, &calls)
+		defer srv.Close()
+
+		u, err := url.Parse(srv.URL)
+		assert.MustOk(t, err)
+
+		cli := rest.NewClient(&http.Client{}, u, rest.WithRetry(3, time.Millisecond, 5*time.Millisecond))
+		req, err := cli.NewRequest(context.Background(), http.MethodPut, "/foo", strings.NewReader("bar"), nil)
+		assert.MustOk(t, err)
+
+		resp, err := cli.Do(req)
+		assert.MustOk(t, err)
+		b, err := ioutil.ReadAll(resp.Body)
+		assert.MustOk(t, err)
+		assert.Equals(t, http.StatusOK, resp.StatusCode)
+		assert.Equals(t, "bar", string(b))
+		assert.Equals(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should give up after the maximum number of retries", func(t *testing.T) {
+		var calls int32
+		srv := newServer(10, &calls)
+		defer srv.Close()
+
+		u, err := url.Parse(srv.URL)
+		assert.MustOk(t, err)
+
+		cli := rest.NewClient(&http.Client{}, u, rest.WithRetry(2, time.Millisecond, time.Millisecond))
+		req, err := cli.NewRequest(context.Background(), http.MethodGet, "/foo", nil, nil)
+		assert.MustOk(t, err)
+
+		resp, err := cli.Do(req)
+		assert.MustOk(t, err)
+		assert.Equals(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equals(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should not retry POST requests", func(t *testing.T) {
+		var calls int32
+		srv := newServer(1, &calls)
+		defer srv.Close()
+
+		u, err := url.Parse(srv.URL)
+		assert.MustOk(t, err)
+
+		cli := rest.NewClient(&http.Client{}, u, rest.WithRetry(3, time.Millisecond, time.Millisecond))
+		req, err := cli.NewRequest(context.Background(), http.MethodPost, "/foo", nil, nil)
+		assert.MustOk(t, err)
+
+		resp, err := cli.Do(req)
+		assert.MustOk(t, err)
+		assert.Equals(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equals(t, int32(1), atomic.LoadInt32(&calls))
+	})
+	t.Run("should not retry requests marked with WithoutRetry", func(t *testing.T) {
+		var calls int32
+		srv := newServer(1, &calls)
+		defer srv.Close()
+
+		u, err := url.Parse(srv.URL)
+		assert.MustOk(t, err)
+
+		cli := rest.NewClient(&http.Client{}, u, rest.WithRetry(3, time.Millisecond, time.Millisecond))
+		req, err := cli.NewRequest(rest.WithoutRetry(context.Background()), http.MethodDelete, "/foo", nil, nil)
+		assert.MustOk(t, err)
+
+		resp, err := cli.Do(req)
+		assert.MustOk(t, err)
+		assert.Equals(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equals(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
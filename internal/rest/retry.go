@@ -0,0 +1,83 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package rest
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// retryPolicy describes how failed idempotent requests are retried.
+type retryPolicy struct {
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// WithRetry makes the Client retry idempotent requests up to maxRetries
+// times when the server cannot be reached or is temporarily unavailable,
+// waiting between attempts with an exponential backoff starting at
+// minBackoff and capped at maxBackoff.
+func WithRetry(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(cli *Client) {
+		cli.retry = retryPolicy{
+			maxRetries: maxRetries,
+			minBackoff: minBackoff,
+			maxBackoff: maxBackoff,
+		}
+	}
+}
+
+// backoff returns the delay before the retry following the given attempt.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	d := p.minBackoff
+	for i := 0; i < attempt && (p.maxBackoff <= 0 || d < p.maxBackoff); i++ {
+		d *= 2
+	}
+	if p.maxBackoff > 0 && d > p.maxBackoff {
+		d = p.maxBackoff
+	}
+	return d
+}
+
+type noRetryKey struct{}
+
+// WithoutRetry returns a copy of ctx which prevents requests using it from
+// being retried, for requests whose method is idempotent but whose effect
+// is not, such as deleting an element by its index.
+func WithoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// idempotent reports whether the request can be safely sent more than once.
+func idempotent(req *http.Request) bool {
+	if noRetry, _ := req.Context().Value(noRetryKey{}).(bool); noRetry {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryable reports whether a request that resulted in resp and err should
+// be retried, which is the case for transport errors, such as a refused
+// connection while the server is starting, and for gateway errors.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
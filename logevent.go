@@ -0,0 +1,134 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LogEventKind is the kind of event described by a LogEvent.
+type LogEventKind string
+
+// The supported LogEventKind values.
+const (
+	// LogOther is any log line not recognised by ParseLog.
+	LogOther LogEventKind = "other"
+
+	// LogImposterCreated is logged when an Imposter starts listening.
+	LogImposterCreated LogEventKind = "imposterCreated"
+
+	// LogImposterDeleted is logged when an Imposter stops listening.
+	LogImposterDeleted LogEventKind = "imposterDeleted"
+
+	// LogRequestReceived is logged for every request received by an Imposter.
+	LogRequestReceived LogEventKind = "requestReceived"
+
+	// LogStubMatched is logged at the debug level when a request matches
+	// the predicates of a Stub.
+	LogStubMatched LogEventKind = "stubMatched"
+
+	// LogNoMatch is logged at the debug level when a request matches no
+	// Stub and is sent the default response of its Imposter.
+	LogNoMatch LogEventKind = "noMatch"
+
+	// LogProxyCall is logged at the debug level when a proxy response
+	// forwards a request to its destination.
+	LogProxyCall LogEventKind = "proxyCall"
+
+	// LogInjectionError is logged when the JavaScript of an inject
+	// predicate or response fails.
+	LogInjectionError LogEventKind = "injectionError"
+)
+
+// LogEvent is a Log parsed by ParseLog.
+type LogEvent struct {
+	Log
+
+	// Kind is the kind of the event.
+	Kind LogEventKind
+
+	// Proto and Port are the protocol and port of the Imposter that logged
+	// the event, or "mb" and the port of mountebank for its own logs.
+	Proto string
+	Port  int
+
+	// Name is the name of the Imposter that logged the event, if any.
+	Name string
+
+	// ClientAddr is the address of the client that sent the request of
+	// LogRequestReceived and LogProxyCall events, e.g. "::ffff:172.17.0.1:56912".
+	ClientAddr string
+
+	// Method and Path are the method and path of the HTTP request of
+	// LogRequestReceived events.
+	Method string
+	Path   string
+
+	// Destination is the URL requests are forwarded to by LogProxyCall events.
+	Destination string
+
+	// Detail is the rest of the message after the Imposter prefix, which
+	// holds the JSON of the matched predicates of LogStubMatched events,
+	// the data of TCP LogRequestReceived events and the error of
+	// LogInjectionError events.
+	Detail string
+}
+
+var (
+	// logPrefix matches the prefix of the logs written by an Imposter, such
+	// as "[http:8080 name]", or by mountebank itself, such as "[mb:2525]".
+	logPrefix = regexp.MustCompile(`(?s)^\[(\w+):(\d+)(?: ([^\]]*))?\] ?(.*)$`)
+
+	logRequest = regexp.MustCompile(`(?s)^(\S+) => (.*)$`)
+	logProxy   = regexp.MustCompile(`(?s)^Proxy (\S+) => (.*) => (\S+)$`)
+)
+
+// ParseLog parses a Log written by mountebank into a LogEvent, whose Kind is
+// LogOther if the message is not recognised.
+func ParseLog(l Log) LogEvent {
+	ev := LogEvent{Log: l, Kind: LogOther, Detail: l.Message}
+
+	m := logPrefix.FindStringSubmatch(l.Message)
+	if m == nil {
+		return ev
+	}
+	ev.Proto = m[1]
+	ev.Port, _ = strconv.Atoi(m[2])
+	ev.Name = m[3]
+	ev.Detail = m[4]
+
+	switch msg := ev.Detail; {
+	case msg == "Open for business...":
+		ev.Kind = LogImposterCreated
+	case msg == "Ciao for now":
+		ev.Kind = LogImposterDeleted
+	case strings.HasPrefix(msg, "using predicate match: "):
+		ev.Kind = LogStubMatched
+		ev.Detail = strings.TrimPrefix(msg, "using predicate match: ")
+	case strings.HasPrefix(msg, "no predicate match"):
+		ev.Kind = LogNoMatch
+	case strings.HasPrefix(msg, "injection X"):
+		ev.Kind = LogInjectionError
+	case logProxy.MatchString(msg):
+		pm := logProxy.FindStringSubmatch(msg)
+		ev.Kind = LogProxyCall
+		ev.ClientAddr = pm[1]
+		ev.Detail = pm[2]
+		ev.Destination = pm[3]
+	case logRequest.MatchString(msg):
+		rm := logRequest.FindStringSubmatch(msg)
+		ev.Kind = LogRequestReceived
+		ev.ClientAddr = rm[1]
+		ev.Detail = rm[2]
+		if ev.Proto == "http" || ev.Proto == "https" {
+			if i := strings.IndexByte(rm[2], ' '); i > 0 {
+				ev.Method, ev.Path = rm[2][:i], rm[2][i+1:]
+			}
+		}
+	}
+
+	return ev
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo_test
+
+import (
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+)
+
+func TestParseLog(t *testing.T) {
+	cases := []struct {
+		Description string
+		Log         mbgo.Log
+		Expected    mbgo.LogEvent
+	}{
+		{
+			Description: "should parse the logs of mountebank itself",
+			Log:         mbgo.Log{Level: "info", Message: "[mb:2525] mountebank v2.1.2 now taking orders"},
+			Expected: mbgo.LogEvent{
+				Kind:   mbgo.LogOther,
+				Proto:  "mb",
+				Port:   2525,
+				Detail: "mountebank v2.1.2 now taking orders",
+			},
+		},
+		{
+			Description: "should leave unprefixed logs unparsed",
+			Log:         mbgo.Log{Level: "warn", Message: "something happened"},
+			Expected: mbgo.LogEvent{
+				Kind:   mbgo.LogOther,
+				Detail: "something happened",
+			},
+		},
+		{
+			Description: "should parse imposter creation",
+			Log:         mbgo.Log{Level: "info", Message: "[http:8080 test imposter] Open for business..."},
+			Expected: mbgo.LogEvent{
+				Kind:   mbgo.LogImposterCreated,
+				Proto:  "http",
+				Port:   8080,
+				Name:   "test imposter",
+				Detail: "Open for business...",
+			},
+		},
+		{
+			Description: "should parse imposter deletion",
+			Log:         mbgo.Log{Level: "info", Message: "[tcp:8081] Ciao for now"},
+			Expected: mbgo.LogEvent{
+				Kind:   mbgo.LogImposterDeleted,
+				Proto:  "tcp",
+				Port:   8081,
+				Detail: "Ciao for now",
+			},
+		},
+		{
+			Description: "should parse http requests",
+			Log:         mbgo.Log{Level: "info", Message: "[http:8080] ::ffff:172.17.0.1:56912 => GET /foo?bar=baz"},
+			Expected: mbgo.LogEvent{
+				Kind:       mbgo.LogRequestReceived,
+				Proto:      "http",
+				Port:       8080,
+				ClientAddr: "::ffff:172.17.0.1:56912",
+				Method:     "GET",
+				Path:       "/foo?bar=baz",
+				Detail:     "GET /foo?bar=baz",
+			},
+		},
+		{
+			Description: "should parse tcp requests",
+			Log:         mbgo.Log{Level: "info", Message: "[tcp:8081] 172.17.0.1:56912 => SGVsbG8="},
+			Expected: mbgo.LogEvent{
+				Kind:       mbgo.LogRequestReceived,
+				Proto:      "tcp",
+				Port:       8081,
+				ClientAddr: "172.17.0.1:56912",
+				Detail:     "SGVsbG8=",
+			},
+		},
+		{
+			Description: "should parse stub matches",
+			Log:         mbgo.Log{Level: "debug", Message: `[http:8080] using predicate match: [{"equals":{"path":"/foo"}}]`},
+			Expected: mbgo.LogEvent{
+				Kind:   mbgo.LogStubMatched,
+				Proto:  "http",
+				Port:   8080,
+				Detail: `[{"equals":{"path":"/foo"}}]`,
+			},
+		},
+		{
+			Description: "should parse proxy calls",
+			Log:         mbgo.Log{Level: "debug", Message: `[http:8080] Proxy ::ffff:172.17.0.1:56912 => {"method":"GET","path":"/foo"} => http://example.com`},
+			Expected: mbgo.LogEvent{
+				Kind:        mbgo.LogProxyCall,
+				Proto:       "http",
+				Port:        8080,
+				ClientAddr:  "::ffff:172.17.0.1:56912",
+				Destination: "http://example.com",
+				Detail:      `{"method":"GET","path":"/foo"}`,
+			},
+		},
+		{
+			Description: "should parse injection errors",
+			Log:         mbgo.Log{Level: "error", Message: "[http:8080] injection X=> ReferenceError: foo is not defined"},
+			Expected: mbgo.LogEvent{
+				Kind:   mbgo.LogInjectionError,
+				Proto:  "http",
+				Port:   8080,
+				Detail: "injection X=> ReferenceError: foo is not defined",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.Description, func(t *testing.T) {
+			t.Parallel()
+
+			c.Expected.Log = c.Log
+			assert.Equals(t, c.Expected, mbgo.ParseLog(c.Log))
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Logger is used by a Client created using WithLogger to log its calls to
+// mountebank. It is satisfied by *log.Logger, and other logging libraries
+// can be used by adapting them to it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// redacted replaces the value of redacted headers and body fields in logs.
+const redacted = "[REDACTED]"
+
+// defaultRedactedFields are the header and JSON body field names whose
+// values are always redacted by WithLogger.
+var defaultRedactedFields = []string{"key", "cert", "passphrase", "Authorization", apiKeyHeader}
+
+// WithLogger makes the Client log every call it makes to mountebank using l,
+// including the method, path, query, headers and JSON body of the request,
+// followed by the status code, body and latency of the response. The private
+// key, certificate and passphrase of HTTPS Imposters, the Authorization and
+// mountebank API key headers, and any fields set using WithRedactedFields are
+// redacted from the headers and bodies.
+//
+// Logging happens at the position of WithLogger in the options passed to
+// NewClient relative to any Middleware, where the first one passed is the
+// outermost: passing it last logs requests as modified by every other
+// Middleware, and responses before any other Middleware sees them. Request
+// hooks always run before logging.
+func WithLogger(l Logger) ClientOption {
+	return func(cli *Client) {
+		WithMiddleware(loggingMiddleware(l, cli))(cli)
+	}
+}
+
+// WithRedactedFields adds header and JSON body field names, matched case
+// insensitively at any depth, whose values are redacted from the logs of
+// a Client created using WithLogger.
+func WithRedactedFields(names ...string) ClientOption {
+	return func(cli *Client) {
+		cli.redact = append(cli.redact, names...)
+	}
+}
+
+func loggingMiddleware(l Logger, cli *Client) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			redact := make(map[string]bool, len(defaultRedactedFields)+len(cli.redact))
+			for _, name := range defaultRedactedFields {
+				redact[strings.ToLower(name)] = true
+			}
+			for _, name := range cli.redact {
+				redact[strings.ToLower(name)] = true
+			}
+
+			var reqBody []byte
+			if req.GetBody != nil {
+				if body, err := req.GetBody(); err == nil {
+					reqBody, _ = ioutil.ReadAll(body)
+					body.Close()
+				}
+			}
+			l.Printf("mbgo: -> %s %s query=%q headers=%s body=%s",
+				req.Method, req.URL.Path, req.URL.RawQuery, redactHeader(req.Header, redact), redactBody(reqBody, redact))
+
+			start := time.Now()
+			resp, err := next(req)
+			latency := time.Since(start)
+			if err != nil {
+				l.Printf("mbgo: <- %s %s error=%q latency=%s", req.Method, req.URL.Path, err, latency)
+				return resp, err
+			}
+
+			var respBody []byte
+			if resp.Body != nil {
+				respBody, err = ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+				resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+				if err != nil {
+					return resp, err
+				}
+			}
+			l.Printf("mbgo: <- %s %s status=%d body=%s latency=%s",
+				req.Method, req.URL.Path, resp.StatusCode, redactBody(respBody, redact), latency)
+
+			return resp, nil
+		}
+	}
+}
+
+// redactHeader formats the given header with the values of redacted names replaced.
+func redactHeader(h http.Header, redact map[string]bool) string {
+	out := make(map[string]interface{}, len(h))
+	for name, vs := range h {
+		if redact[strings.ToLower(name)] {
+			out[name] = redacted
+			continue
+		}
+		out[name] = strings.Join(vs, ", ")
+	}
+	b, _ := json.Marshal(out)
+	return string(b)
+}
+
+// redactBody returns the given body with the values of redacted fields
+// replaced, or the body unchanged if it is not a JSON object or array.
+func redactBody(b []byte, redact map[string]bool) string {
+	if len(b) == 0 {
+		return "<empty>"
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return string(b)
+	}
+	out, err := json.Marshal(redactValue(v, redact))
+	if err != nil {
+		return string(b)
+	}
+	return string(out)
+}
+
+func redactValue(v interface{}, redact map[string]bool) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, vv := range t {
+			if redact[strings.ToLower(k)] {
+				t[k] = redacted
+			} else {
+				t[k] = redactValue(vv, redact)
+			}
+		}
+	case []interface{}:
+		for i, vv := range t {
+			t[i] = redactValue(vv, redact)
+		}
+	}
+	return v
+}
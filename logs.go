@@ -0,0 +1,117 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+import (
+	"context"
+	"regexp"
+	"time"
+)
+
+// logLevels orders the levels of mountebank logs by severity.
+var logLevels = map[string]int{
+	"debug": 0,
+	"info":  1,
+	"warn":  2,
+	"error": 3,
+}
+
+// defaultTailInterval is the interval between polls used by TailLogs by default.
+const defaultTailInterval = time.Second
+
+// TailOptions configures the Log values streamed by Client.TailLogs.
+type TailOptions struct {
+	// Start is the index of the first Log to stream, or the Logs written
+	// after the call to TailLogs are streamed if less than zero.
+	Start int
+
+	// Interval is the time between two polls of the mountebank logs,
+	// which defaults to one second if zero.
+	Interval time.Duration
+
+	// MinLevel excludes the Logs whose level is lower than it, out of
+	// "debug", "info", "warn" and "error", if set.
+	MinLevel string
+
+	// Message excludes the Logs whose message does not match it, if set.
+	Message *regexp.Regexp
+
+	// Port excludes the Logs which are not written by the Imposter on the
+	// given port, whose messages start with a prefix such as
+	// "[http:8080 name]", if non-zero.
+	Port int
+
+	// OnError is called with any error returned while polling the logs,
+	// after which polling continues. Errors are ignored if it is nil.
+	OnError func(error)
+}
+
+// matches reports whether the Log l passes the filters of the options.
+func (opts TailOptions) matches(l Log) bool {
+	if min, ok := logLevels[opts.MinLevel]; ok {
+		if lvl, ok := logLevels[l.Level]; ok && lvl < min {
+			return false
+		}
+	}
+	if opts.Port != 0 && ParseLog(l).Port != opts.Port {
+		return false
+	}
+	if opts.Message != nil && !opts.Message.MatchString(l.Message) {
+		return false
+	}
+	return true
+}
+
+// TailLogs streams the Log values written by mountebank which pass the filters
+// of opts, by polling the logs using Logs from the last seen index. The returned
+// channel is closed once ctx is done.
+func (cli *Client) TailLogs(ctx context.Context, opts TailOptions) <-chan Log {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultTailInterval
+	}
+
+	ch := make(chan Log)
+	go func() {
+		defer close(ch)
+
+		next := opts.Start
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+
+		for {
+			if next < 0 {
+				// skip the existing logs until they can be counted
+				logs, err := cli.Logs(ctx, -1, -1)
+				if err == nil {
+					next = len(logs)
+				} else if opts.OnError != nil && ctx.Err() == nil {
+					opts.OnError(err)
+				}
+			} else {
+				logs, err := cli.Logs(ctx, next, -1)
+				if err != nil && opts.OnError != nil && ctx.Err() == nil {
+					opts.OnError(err)
+				}
+				for _, l := range logs {
+					next++
+					if !opts.matches(l) {
+						continue
+					}
+					select {
+					case ch <- l:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+)
+
+// logServer fakes the logs resource of mountebank, failing every request
+// while fail is set.
+type logServer struct {
+	mu   sync.Mutex
+	logs []mbgo.Log
+	fail bool
+}
+
+func (s *logServer) write(level, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, mbgo.Log{Level: level, Message: msg})
+}
+
+func (s *logServer) middleware(next mbgo.RoundTripFunc) mbgo.RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.fail {
+			return nil, errors.New("connection refused")
+		}
+		logs := s.logs
+		if v := req.URL.Query().Get("startIndex"); v != "" {
+			start, _ := strconv.Atoi(v)
+			logs = logs[start:]
+		}
+		b, _ := json.Marshal(map[string]interface{}{"logs": logs})
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(string(b))),
+			Request:    req,
+		}, nil
+	}
+}
+
+func TestClient_TailLogs(t *testing.T) {
+	srv := &logServer{}
+	srv.write("info", "[mb:2525] mountebank v2.1.2 now taking orders")
+	srv.write("info", "[http:8080 foo] Open for business...")
+
+	mb := mbgo.NewClient(http.DefaultClient, nil, mbgo.WithMiddleware(srv.middleware))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var errs []error
+	var errsMu sync.Mutex
+	logs := mb.TailLogs(ctx, mbgo.TailOptions{
+		Start:    -1,
+		Interval: time.Millisecond,
+		MinLevel: "info",
+		Message:  regexp.MustCompile(`GET`),
+		Port:     8080,
+		OnError: func(err error) {
+			errsMu.Lock()
+			defer errsMu.Unlock()
+			errs = append(errs, err)
+		},
+	})
+
+	// wait for the existing logs to be skipped
+	time.Sleep(20 * time.Millisecond)
+
+	srv.write("info", "[http:8080 foo] ::ffff:172.17.0.1:50000 => GET /foo")
+	srv.write("debug", "[http:8080 foo] ::ffff:172.17.0.1:50000 => GET /debug")
+	srv.write("info", "[http:8081 bar] ::ffff:172.17.0.1:50000 => GET /bar")
+	srv.write("info", "[http:8080 foo] ::ffff:172.17.0.1:50000 => POST /foo")
+	assert.Equals(t, "[http:8080 foo] ::ffff:172.17.0.1:50000 => GET /foo", (<-logs).Message)
+
+	srv.mu.Lock()
+	srv.fail = true
+	srv.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	srv.mu.Lock()
+	srv.fail = false
+	srv.mu.Unlock()
+
+	srv.write("warn", "[http:8080 foo] ::ffff:172.17.0.1:50000 => GET /baz")
+	assert.Equals(t, "[http:8080 foo] ::ffff:172.17.0.1:50000 => GET /baz", (<-logs).Message)
+
+	errsMu.Lock()
+	assert.Equals(t, true, len(errs) > 0)
+	errsMu.Unlock()
+
+	cancel()
+	for range logs {
+		t.Fatal("expected no more logs")
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+import "context"
+
+// Operation describes the Client call a request is sent for, which is made
+// available to any Middleware through the context of the request.
+type Operation struct {
+	// Name is the name of the Client method, e.g. "Create" or "AddStub".
+	Name string
+
+	// Port is the port of the Imposter the call applies to, or zero for
+	// calls that are not specific to an Imposter.
+	Port int
+
+	// Proto is the protocol of the Imposter the call applies to, which is
+	// only known when creating an Imposter and is otherwise empty.
+	Proto string
+}
+
+type operationKey struct{}
+
+func withOperation(ctx context.Context, op Operation) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+// OperationFromContext returns the Operation stored in the context of a
+// request sent by a Client, reporting false if there is none.
+func OperationFromContext(ctx context.Context) (Operation, bool) {
+	op, ok := ctx.Value(operationKey{}).(Operation)
+	return op, ok
+}
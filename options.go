@@ -0,0 +1,139 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/senseyeio/mbgo/internal/rest"
+)
+
+// ClientOption configures optional behaviour of a Client created by NewClient.
+type ClientOption func(*Client)
+
+// WithValidation makes the Client validate Imposter values with
+// Imposter.Validate before sending them to mountebank in Client.Create
+// and Client.Overwrite, returning a *ValidationError without making a
+// request if any are invalid.
+func WithValidation() ClientOption {
+	return func(cli *Client) {
+		cli.validate = true
+	}
+}
+
+// DecodeMode defines how a Client handles the values returned by mountebank
+// that cannot be decoded, such as a malformed requestFrom address or a header
+// value that is not a string.
+type DecodeMode int
+
+// The supported DecodeMode values.
+const (
+	// DecodeStrict fails the call, returning an error naming the field
+	// that could not be decoded. It is the default.
+	DecodeStrict DecodeMode = iota
+
+	// DecodeLenient leaves the field unset and keeps its raw value in the
+	// Extra field of the value it belongs to instead.
+	DecodeLenient
+)
+
+// WithDecodeMode sets the DecodeMode used by the Client to decode Imposters.
+//
+// A Client created without it uses DecodeStrict, so calls returning values
+// which earlier versions of the Client ignored, such as a form field that is
+// not a string, now fail. Use DecodeLenient to decode such Imposters anyway.
+func WithDecodeMode(mode DecodeMode) ClientOption {
+	return func(cli *Client) {
+		cli.decodeMode = mode
+	}
+}
+
+// WithRetry makes the Client retry idempotent calls, those using the GET,
+// PUT and DELETE methods, up to maxRetries times if mountebank cannot be
+// reached or responds with a gateway error. Retries are delayed using an
+// exponential backoff starting at minBackoff and capped at maxBackoff.
+//
+// Calls using the POST method, such as Client.Create, and the removal of a
+// Stub by its index, are never retried; use Client.WaitReady to wait for
+// mountebank to start before making them.
+func WithRetry(maxRetries int, minBackoff, maxBackoff time.Duration) ClientOption {
+	return func(cli *Client) {
+		cli.restOpts = append(cli.restOpts, rest.WithRetry(maxRetries, minBackoff, maxBackoff))
+	}
+}
+
+// RequestHook is called with every request built by the Client before it is
+// sent to mountebank, for instance to add headers to it. Returning an error
+// aborts the call, which then returns the error.
+type RequestHook func(*http.Request) error
+
+// RoundTripFunc sends a request to mountebank and returns its response.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wraps the sending of every request to mountebank, which allows
+// for logging, tracing or collecting metrics about the calls of a Client.
+// A Middleware must call next to send the request, unless it short-circuits
+// the call with its own response or error.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithRequestHook adds a RequestHook to the Client. Hooks are called in the
+// order they were added, after the default headers have been set.
+func WithRequestHook(hook RequestHook) ClientOption {
+	return func(cli *Client) {
+		cli.restOpts = append(cli.restOpts, rest.WithRequestHook(hook))
+	}
+}
+
+// WithMiddleware adds a Middleware to the Client, where the first Middleware
+// added is the outermost one. Each Middleware is called once per request,
+// wrapping any retries made when using WithRetry.
+func WithMiddleware(mw Middleware) ClientOption {
+	return func(cli *Client) {
+		cli.restOpts = append(cli.restOpts, rest.WithMiddleware(func(next rest.DoFunc) rest.DoFunc {
+			return rest.DoFunc(mw(RoundTripFunc(next)))
+		}))
+	}
+}
+
+// WithHeader sets the given header on every request sent by the Client,
+// for instance to authenticate with a reverse proxy in front of mountebank.
+func WithHeader(name, value string) ClientOption {
+	return WithRequestHook(func(req *http.Request) error {
+		req.Header.Set(name, value)
+		return nil
+	})
+}
+
+// WithDynamicHeader sets the given header on every request sent by the
+// Client to the value returned by fn, which is called with the context of
+// the request. Returning an error aborts the call, and returning an empty
+// value leaves the header unset.
+func WithDynamicHeader(name string, fn func(ctx context.Context) (string, error)) ClientOption {
+	return WithRequestHook(func(req *http.Request) error {
+		v, err := fn(req.Context())
+		if err != nil {
+			return err
+		}
+		if v != "" {
+			req.Header.Set(name, v)
+		}
+		return nil
+	})
+}
+
+// WithUserAgent sets the User-Agent header of every request sent by the Client.
+func WithUserAgent(ua string) ClientOption {
+	return WithHeader("User-Agent", ua)
+}
+
+// apiKeyHeader is the header checked by mountebank when started using --apikey.
+const apiKeyHeader = "x-api-key"
+
+// WithAPIKey authenticates every request sent by the Client using the API key
+// required by mountebank when it is started using the --apikey option.
+func WithAPIKey(key string) ClientOption {
+	return WithHeader(apiKeyHeader, key)
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+)
+
+// respondWith returns a Middleware that never sends requests to mountebank,
+// recording them and responding with the given status code and body instead.
+func respondWith(reqs *[]*http.Request, code int, body string) mbgo.Middleware {
+	return func(next mbgo.RoundTripFunc) mbgo.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			*reqs = append(*reqs, req)
+			return &http.Response{
+				StatusCode: code,
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		}
+	}
+}
+
+func TestNewClient_Options(t *testing.T) {
+	t.Run("should set the configured headers on every request", func(t *testing.T) {
+		var reqs []*http.Request
+		mb := mbgo.NewClient(http.DefaultClient, nil,
+			mbgo.WithAPIKey("secret"),
+			mbgo.WithUserAgent("mbgo-test"),
+			mbgo.WithHeader("X-Static", "static"),
+			mbgo.WithDynamicHeader("X-Dynamic", func(ctx context.Context) (string, error) {
+				return "dynamic", nil
+			}),
+			mbgo.WithMiddleware(respondWith(&reqs, http.StatusOK, `{"version":"2.1.2"}`)),
+		)
+
+		cfg, err := mb.Config(context.Background())
+		assert.MustOk(t, err)
+		assert.Equals(t, "2.1.2", cfg.Version)
+
+		assert.Equals(t, 1, len(reqs))
+		assert.Equals(t, http.Header{
+			"Accept":     {"application/json"},
+			"X-Api-Key":  {"secret"},
+			"User-Agent": {"mbgo-test"},
+			"X-Static":   {"static"},
+			"X-Dynamic":  {"dynamic"},
+		}, reqs[0].Header)
+	})
+
+	t.Run("should abort the call if a request hook fails", func(t *testing.T) {
+		var reqs []*http.Request
+		hookErr := errors.New("no token")
+		mb := mbgo.NewClient(http.DefaultClient, nil,
+			mbgo.WithDynamicHeader("Authorization", func(ctx context.Context) (string, error) {
+				return "", hookErr
+			}),
+			mbgo.WithMiddleware(respondWith(&reqs, http.StatusOK, `{}`)),
+		)
+
+		_, err := mb.Config(context.Background())
+		assert.Equals(t, hookErr, err)
+		assert.Equals(t, 0, len(reqs))
+	})
+
+	t.Run("should call middleware in the order they were added", func(t *testing.T) {
+		var calls []string
+		trace := func(name string) mbgo.Middleware {
+			return func(next mbgo.RoundTripFunc) mbgo.RoundTripFunc {
+				return func(req *http.Request) (*http.Response, error) {
+					calls = append(calls, name+" before")
+					resp, err := next(req)
+					calls = append(calls, name+" after")
+					return resp, err
+				}
+			}
+		}
+
+		var reqs []*http.Request
+		mb := mbgo.NewClient(http.DefaultClient, nil,
+			mbgo.WithMiddleware(trace("outer")),
+			mbgo.WithMiddleware(trace("inner")),
+			mbgo.WithMiddleware(respondWith(&reqs, http.StatusOK, `{}`)),
+		)
+
+		_, err := mb.Config(context.Background())
+		assert.MustOk(t, err)
+		assert.Equals(t, []string{"outer before", "inner before", "inner after", "outer after"}, calls)
+	})
+}
+
+type bufferLogger struct {
+	lines []string
+}
+
+func (l *bufferLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestWithLogger(t *testing.T) {
+	var reqs []*http.Request
+	logger := &bufferLogger{}
+	mb := mbgo.NewClient(http.DefaultClient, nil,
+		mbgo.WithAPIKey("secret"),
+		mbgo.WithLogger(logger),
+		mbgo.WithRedactedFields("X-Custom"),
+		mbgo.WithMiddleware(respondWith(&reqs, http.StatusBadRequest,
+			`{"errors":[{"code":"bad data","message":"invalid key"}]}`)),
+	)
+
+	_, err := mb.Create(context.Background(), mbgo.Imposter{
+		Port:  8443,
+		Proto: "https",
+		Stubs: []mbgo.Stub{
+			{
+				Responses: []mbgo.Response{
+					{Type: "is", Value: mbgo.HTTPResponse{
+						StatusCode: http.StatusOK,
+						Body:       map[string]interface{}{"x-custom": "hidden", "key": "hidden"},
+					}},
+				},
+			},
+		},
+	})
+	assert.Equals(t, "bad data: invalid key", err.Error())
+
+	assert.Equals(t, 2, len(logger.lines))
+	for _, line := range logger.lines {
+		assert.Equals(t, false, strings.Contains(line, "secret"))
+		assert.Equals(t, false, strings.Contains(line, "hidden"))
+	}
+	assert.Equals(t, true, strings.HasPrefix(logger.lines[0], `mbgo: -> POST /imposters query="" headers=`))
+	assert.Equals(t, true, strings.Contains(logger.lines[0], `"X-Api-Key":"[REDACTED]"`))
+	assert.Equals(t, true, strings.Contains(logger.lines[0], `"port":8443`))
+	assert.Equals(t, true, strings.HasPrefix(logger.lines[1],
+		`mbgo: <- POST /imposters status=400 body={"errors":[{"code":"bad data","message":"invalid key"}]} latency=`))
+}
@@ -0,0 +1,22 @@
+module github.com/senseyeio/mbgo/otelmbgo
+
+go 1.18
+
+require (
+	github.com/senseyeio/mbgo v0.0.0-20261018142853-41397116ee51
+	go.opentelemetry.io/otel v1.14.0
+	go.opentelemetry.io/otel/metric v0.37.0
+	go.opentelemetry.io/otel/sdk v1.14.0
+	go.opentelemetry.io/otel/sdk/metric v0.37.0
+	go.opentelemetry.io/otel/trace v1.14.0
+)
+
+require (
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	golang.org/x/sys v0.5.0 // indirect
+)
+
+// Develop against the mbgo module of the enclosing checkout. The required
+// version above is the first to provide Client middleware and operations.
+replace github.com/senseyeio/mbgo => ../
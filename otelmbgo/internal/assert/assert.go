@@ -0,0 +1,29 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+// Package assert is used internally by the otelmbgo tests to make basic
+// assertions, as the mbgo test helpers belong to another module.
+package assert
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Equals asserts deep equality between an actual and expected value.
+func Equals(tb testing.TB, expected, actual interface{}) {
+	tb.Helper()
+
+	if !reflect.DeepEqual(expected, actual) {
+		tb.Errorf("\n\n\texpected: %#v\n\n\tactual: %#v\n\n", expected, actual)
+	}
+}
+
+// MustOk fails the test now if an err is not nil.
+func MustOk(tb testing.TB, err error) {
+	tb.Helper()
+
+	if err != nil {
+		tb.Fatalf("fatal error: %#v\n\n", err)
+	}
+}
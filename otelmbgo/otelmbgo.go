@@ -0,0 +1,178 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+// Package otelmbgo instruments an mbgo Client with OpenTelemetry tracing and
+// metrics. It is a separate module so that mbgo itself does not depend on
+// OpenTelemetry.
+//
+// Every call made by an instrumented Client emits a client span named after
+// the Client method, e.g. "mbgo.Create", with attributes for the Imposter
+// port and protocol when known, the HTTP status code of the response and the
+// code of any error returned by mountebank. The duration and errors of each
+// call are also recorded as metrics.
+//
+// The global OpenTelemetry providers are used by default, which do nothing
+// unless the application has configured them.
+package otelmbgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/senseyeio/mbgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies this package as the source of its telemetry.
+const instrumentationName = "github.com/senseyeio/mbgo/otelmbgo"
+
+// Attribute keys set on spans and metrics.
+const (
+	OperationKey  = attribute.Key("mbgo.operation")
+	PortKey       = attribute.Key("mbgo.imposter.port")
+	ProtocolKey   = attribute.Key("mbgo.imposter.protocol")
+	ErrorCodeKey  = attribute.Key("mbgo.error.code")
+	MethodKey     = attribute.Key("http.request.method")
+	StatusCodeKey = attribute.Key("http.response.status_code")
+)
+
+// Names of the recorded metrics.
+const (
+	DurationMetric = "mbgo.client.call.duration"
+	ErrorsMetric   = "mbgo.client.call.errors"
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// Option configures the instrumentation.
+type Option func(*config)
+
+// WithTracerProvider sets the TracerProvider used to create spans, instead
+// of the global one.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the MeterProvider used to record metrics, instead
+// of the global one.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// Instrument returns an mbgo.ClientOption which instruments the Client it
+// is passed to with the Middleware returned by Middleware.
+func Instrument(opts ...Option) mbgo.ClientOption {
+	return mbgo.WithMiddleware(Middleware(opts...))
+}
+
+// Middleware returns an mbgo.Middleware which traces and measures every call
+// made by a Client.
+func Middleware(opts ...Option) mbgo.Middleware {
+	c := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  global.MeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	tracer := c.tracerProvider.Tracer(instrumentationName)
+	meter := c.meterProvider.Meter(instrumentationName)
+
+	// Instrument creation only fails for invalid names, in which case
+	// the returned no-op instrument is still safe to use.
+	duration, _ := meter.Float64Histogram(DurationMetric,
+		instrument.WithDescription("Duration of mbgo client calls to mountebank."),
+		instrument.WithUnit("s"))
+	errorCount, _ := meter.Int64Counter(ErrorsMetric,
+		instrument.WithDescription("Number of mbgo client calls to mountebank that failed."))
+
+	return func(next mbgo.RoundTripFunc) mbgo.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			op, ok := mbgo.OperationFromContext(req.Context())
+			if !ok {
+				op.Name = "Request"
+			}
+
+			attrs := []attribute.KeyValue{OperationKey.String(op.Name)}
+			if op.Port != 0 {
+				attrs = append(attrs, PortKey.Int(op.Port))
+			}
+			if op.Proto != "" {
+				attrs = append(attrs, ProtocolKey.String(op.Proto))
+			}
+
+			ctx, span := tracer.Start(req.Context(), "mbgo."+op.Name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+				trace.WithAttributes(MethodKey.String(req.Method)))
+			defer span.End()
+
+			start := time.Now()
+			resp, err := next(req.WithContext(ctx))
+			elapsed := time.Since(start).Seconds()
+
+			failed := err != nil
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			} else {
+				attrs = append(attrs, StatusCodeKey.Int(resp.StatusCode))
+				if resp.StatusCode >= http.StatusBadRequest {
+					failed = true
+					span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+					if code := peekErrorCode(resp); code != "" {
+						attrs = append(attrs, ErrorCodeKey.String(code))
+					}
+				}
+				span.SetAttributes(attrs...)
+			}
+
+			duration.Record(ctx, elapsed, attrs...)
+			if failed {
+				errorCount.Add(ctx, 1, attrs...)
+			}
+			return resp, err
+		}
+	}
+}
+
+// peekErrorCode returns the code of the first error in a mountebank error
+// response body, leaving the body intact for the Client to decode.
+func peekErrorCode(resp *http.Response) string {
+	if resp.Body == nil {
+		return ""
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return ""
+	}
+
+	var wrap struct {
+		Errors []struct {
+			Code string `json:"code"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(b, &wrap); err != nil || len(wrap.Errors) == 0 {
+		return ""
+	}
+	return wrap.Errors[0].Code
+}
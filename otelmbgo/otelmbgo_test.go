@@ -0,0 +1,82 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package otelmbgo_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/otelmbgo"
+	"github.com/senseyeio/mbgo/otelmbgo/internal/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// respondWith returns a Middleware that never sends requests to mountebank,
+// responding with the given status code and body instead.
+func respondWith(code int, body string) mbgo.Middleware {
+	return func(next mbgo.RoundTripFunc) mbgo.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: code,
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		}
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	spans := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	mb := mbgo.NewClient(http.DefaultClient, nil,
+		otelmbgo.Instrument(otelmbgo.WithTracerProvider(tp), otelmbgo.WithMeterProvider(mp)),
+		mbgo.WithMiddleware(respondWith(http.StatusBadRequest,
+			`{"errors":[{"code":"bad data","message":"invalid value for 'port'"}]}`)),
+	)
+
+	_, err := mb.Create(context.Background(), mbgo.Imposter{Port: 8080, Proto: "http"})
+	assert.Equals(t, "bad data: invalid value for 'port'", err.Error())
+
+	ended := spans.GetSpans()
+	assert.Equals(t, 1, len(ended))
+	assert.Equals(t, "mbgo.Create", ended[0].Name)
+	assert.Equals(t, codes.Error, ended[0].Status.Code)
+	assert.Equals(t, []attribute.KeyValue{
+		otelmbgo.OperationKey.String("Create"),
+		otelmbgo.PortKey.Int(8080),
+		otelmbgo.ProtocolKey.String("http"),
+		otelmbgo.MethodKey.String(http.MethodPost),
+		otelmbgo.StatusCodeKey.Int(http.StatusBadRequest),
+		otelmbgo.ErrorCodeKey.String("bad data"),
+	}, ended[0].Attributes)
+
+	var rm metricdata.ResourceMetrics
+	assert.MustOk(t, reader.Collect(context.Background(), &rm))
+	assert.Equals(t, 1, len(rm.ScopeMetrics))
+
+	names := map[string]bool{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		names[m.Name] = true
+		if m.Name == otelmbgo.ErrorsMetric {
+			sum := m.Data.(metricdata.Sum[int64])
+			assert.Equals(t, int64(1), sum.DataPoints[0].Value)
+		}
+	}
+	assert.Equals(t, map[string]bool{
+		otelmbgo.DurationMetric: true,
+		otelmbgo.ErrorsMetric:   true,
+	}, names)
+}
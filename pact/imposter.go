@@ -0,0 +1,268 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package pact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+
+	"github.com/senseyeio/mbgo"
+)
+
+// Imposter returns an "http" Imposter on the given port with one Stub per
+// Interaction, in order. The Imposter records requests so that they can be
+// checked afterwards with Verify.
+func (p *Pact) Imposter(port int) mbgo.Imposter {
+	imp := mbgo.Imposter{
+		Port:           port,
+		Proto:          "http",
+		Name:           fmt.Sprintf("%s-%s", p.Consumer, p.Provider),
+		RecordRequests: true,
+		Stubs:          make([]mbgo.Stub, len(p.Interactions)),
+	}
+	for i, in := range p.Interactions {
+		imp.Stubs[i] = in.Stub()
+	}
+	return imp
+}
+
+// Stub returns a Stub which matches the Interaction request and responds with
+// its response. Values without matching rules are matched for equality, and
+// values with them are matched as follows:
+//
+//	regex - "matches" predicate with the anchored regular expression.
+//	include - "contains" predicate with the expected substring.
+//	equality - "equals" predicate with the example value.
+//	other - "matches" predicate accepting any value, as mountebank cannot check value types.
+//
+// A request body without matching rules must equal the example exactly, using
+// a "deepEquals" predicate; extra query parameters and headers are allowed.
+// Header predicates are never case-sensitive, as mountebank would then also
+// compare the header names case-sensitively.
+func (in Interaction) Stub() mbgo.Stub {
+	req := in.Request
+	var preds []mbgo.Predicate
+
+	if req.Method != "" {
+		preds = append(preds, mbgo.Predicate{
+			Operator: "equals",
+			Request:  mbgo.HTTPRequest{Method: req.Method},
+		})
+	}
+
+	if req.Path != "" {
+		preds = append(preds, fieldPredicate(req.Rules, "$.path", req.Path, func(v string) mbgo.HTTPRequest {
+			return mbgo.HTTPRequest{Path: v}
+		}))
+	}
+
+	for _, k := range sortedKeys(req.Query) {
+		for _, v := range req.Query[k] {
+			k := k
+			preds = append(preds, fieldPredicate(req.Rules, "$.query."+k, v, func(v string) mbgo.HTTPRequest {
+				return mbgo.HTTPRequest{Query: url.Values{k: {v}}}
+			}))
+		}
+	}
+
+	for _, k := range sortedKeys(req.Headers) {
+		for _, v := range req.Headers[k] {
+			k := k
+			pred := fieldPredicate(nil, "", v, func(v string) mbgo.HTTPRequest {
+				return mbgo.HTTPRequest{Headers: http.Header{k: {v}}}
+			})
+			if ms, ok := req.Rules.lookupHeader(k); ok {
+				pred = matcherPredicate(ms, v, func(v string) mbgo.HTTPRequest {
+					return mbgo.HTTPRequest{Headers: http.Header{k: {v}}}
+				})
+			}
+			preds = append(preds, caseInsensitive(pred))
+		}
+	}
+
+	if req.Body != nil {
+		preds = append(preds, bodyPredicates(req)...)
+	}
+
+	return mbgo.Stub{
+		Predicates: preds,
+		Responses: []mbgo.Response{
+			{
+				Type: "is",
+				Value: mbgo.HTTPResponse{
+					StatusCode: in.Response.Status,
+					Headers:    in.Response.Headers,
+					Body:       in.Response.Body,
+				},
+			},
+		},
+	}
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fieldPredicate builds the predicate for the string value at location using
+// its matching rules, or an "equals" predicate if it has none.
+func fieldPredicate(rules Rules, location, v string, req func(string) mbgo.HTTPRequest) mbgo.Predicate {
+	if ms, ok := rules[location]; ok {
+		return matcherPredicate(ms, v, req)
+	}
+	return mbgo.Predicate{
+		Operator:      "equals",
+		Request:       req(v),
+		CaseSensitive: true,
+	}
+}
+
+func matcherPredicate(ms []Matcher, example string, req func(string) mbgo.HTTPRequest) mbgo.Predicate {
+	preds := make([]mbgo.Predicate, len(ms))
+	for i, m := range ms {
+		switch m.Match {
+		case "regex":
+			preds[i] = mbgo.Predicate{Operator: "matches", Request: req(anchor(m.Regex)), CaseSensitive: true}
+		case "include":
+			preds[i] = mbgo.Predicate{Operator: "contains", Request: req(m.Value), CaseSensitive: true}
+		case "equality":
+			preds[i] = mbgo.Predicate{Operator: "equals", Request: req(example), CaseSensitive: true}
+		default:
+			// the value only needs to be present with the right type
+			preds[i] = mbgo.Predicate{Operator: "matches", Request: req(".*")}
+		}
+	}
+	if len(preds) == 1 {
+		return preds[0]
+	}
+	return mbgo.Predicate{Operator: "and", Request: preds}
+}
+
+// caseInsensitive clears CaseSensitive on p and the predicates it combines.
+func caseInsensitive(p mbgo.Predicate) mbgo.Predicate {
+	p.CaseSensitive = false
+	if preds, ok := p.Request.([]mbgo.Predicate); ok {
+		out := make([]mbgo.Predicate, len(preds))
+		for i, pp := range preds {
+			out[i] = caseInsensitive(pp)
+		}
+		p.Request = out
+	}
+	return p
+}
+
+// anchor wraps a Pact regular expression, which must match the whole value,
+// so that it behaves the same way in a mountebank "matches" predicate.
+func anchor(re string) string {
+	return "^(?:" + re + ")$"
+}
+
+// leaf is a scalar value found at a concrete location within a JSON body.
+type leaf struct {
+	location string
+	value    interface{}
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// leaves flattens the JSON value v found at location into its scalar values,
+// in a deterministic order.
+func leaves(location string, v interface{}) []leaf {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var out []leaf
+		for _, k := range keys {
+			sub := location + "." + k
+			if !identifier.MatchString(k) {
+				sub = fmt.Sprintf("%s[%q]", location, k)
+			}
+			out = append(out, leaves(sub, t[k])...)
+		}
+		return out
+
+	case []interface{}:
+		var out []leaf
+		for i, elem := range t {
+			out = append(out, leaves(fmt.Sprintf("%s[%d]", location, i), elem)...)
+		}
+		return out
+
+	default:
+		return []leaf{{location: location, value: v}}
+	}
+}
+
+// bodyPredicates matches the request body exactly if it has no matching
+// rules, otherwise each of its scalar values is matched individually using a
+// JSONPath selector.
+func bodyPredicates(req Request) []mbgo.Predicate {
+	hasRules := false
+	for key := range req.Rules {
+		if covers("$.body", key) {
+			hasRules = true
+			break
+		}
+	}
+	if !hasRules {
+		return []mbgo.Predicate{{
+			Operator:      "deepEquals",
+			Request:       mbgo.HTTPRequest{Body: req.Body},
+			CaseSensitive: true,
+		}}
+	}
+
+	var preds []mbgo.Predicate
+	for _, l := range leaves("$.body", req.Body) {
+		selector := &mbgo.JSONPath{Selector: "$" + l.location[len("$.body"):]}
+		ms, ok := req.Rules.lookup(l.location)
+		if !ok {
+			preds = append(preds, mbgo.Predicate{
+				Operator:      "equals",
+				Request:       mbgo.HTTPRequest{Body: jsonText(l.value)},
+				JSONPath:      selector,
+				CaseSensitive: true,
+			})
+			continue
+		}
+		pred := matcherPredicate(ms, jsonText(l.value), func(v string) mbgo.HTTPRequest {
+			return mbgo.HTTPRequest{Body: v}
+		})
+		withSelector(&pred, selector)
+		preds = append(preds, pred)
+	}
+	return preds
+}
+
+func withSelector(p *mbgo.Predicate, selector *mbgo.JSONPath) {
+	if sub, ok := p.Request.([]mbgo.Predicate); ok {
+		for i := range sub {
+			withSelector(&sub[i], selector)
+		}
+		return
+	}
+	p.JSONPath = selector
+}
+
+// jsonText returns the text mountebank compares a JSONPath selection with.
+func jsonText(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
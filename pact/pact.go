@@ -0,0 +1,367 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+// Package pact turns Pact consumer contracts into mountebank imposters, and
+// verifies the requests recorded by those imposters against the contract.
+//
+// This allows consumer tests to run against mountebank without a Pact mock
+// server: each interaction is served by a Stub built from its example request
+// and matching rules, and once the tests have run, Verify checks that every
+// interaction was exercised. Provider states are kept on the Interaction for
+// reference but play no part in matching.
+//
+// Both the version 2 and version 3 Pact specification formats are supported.
+// See more information about the specification at:
+// https://github.com/pact-foundation/pact-specification.
+package pact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Matcher is a single Pact matching rule applied to a value.
+type Matcher struct {
+	// Match is the kind of match, e.g. "regex", "type", "include" or "equality".
+	Match string `json:"match"`
+
+	// Regex is the regular expression of a "regex" Matcher.
+	Regex string `json:"regex,omitempty"`
+
+	// Value is the expected substring of an "include" Matcher.
+	Value string `json:"value,omitempty"`
+
+	// Min and Max are the optional array length bounds of a "type" Matcher.
+	Min int `json:"min,omitempty"`
+	Max int `json:"max,omitempty"`
+}
+
+// Rules maps the location of a value in a request to the Matchers applied to
+// it, which must all succeed. Locations use the version 2 notation regardless
+// of the source format, e.g. "$.path", "$.query.page", "$.headers.Accept" and
+// "$.body.items[*].id".
+type Rules map[string][]Matcher
+
+// Request is the request half of an Interaction.
+type Request struct {
+	// Method is the HTTP request method.
+	Method string
+
+	// Path is the path of the request, without the query parameters.
+	Path string
+
+	// Query contains the URL query parameters of the request.
+	Query url.Values
+
+	// Headers contains the HTTP headers of the request.
+	Headers http.Header
+
+	// Body is the decoded JSON body of the request, or nil if absent.
+	Body interface{}
+
+	// Rules are the matching rules used instead of equality for the
+	// values at the given locations.
+	Rules Rules
+}
+
+// Response is the response half of an Interaction.
+type Response struct {
+	// Status is the HTTP status code of the response.
+	Status int
+
+	// Headers contains the HTTP headers of the response.
+	Headers http.Header
+
+	// Body is the decoded JSON body of the response, or nil if absent.
+	Body interface{}
+}
+
+// Interaction is a single request and response pair of the contract.
+type Interaction struct {
+	// Description is the unique description of the Interaction.
+	Description string
+
+	// ProviderStates lists the names of the states the provider should be
+	// in for the Interaction to apply.
+	ProviderStates []string
+
+	// Request is the request expected from the consumer.
+	Request Request
+
+	// Response is the response returned to the consumer.
+	Response Response
+}
+
+// Pact is a contract between a consumer and a provider.
+type Pact struct {
+	// Consumer and Provider are the names of the contract parties.
+	Consumer string
+	Provider string
+
+	// Interactions are the interactions of the contract, in order.
+	Interactions []Interaction
+}
+
+type participantDTO struct {
+	Name string `json:"name"`
+}
+
+type requestDTO struct {
+	Method        string                     `json:"method"`
+	Path          string                     `json:"path"`
+	Query         json.RawMessage            `json:"query"`
+	Headers       map[string]json.RawMessage `json:"headers"`
+	Body          interface{}                `json:"body"`
+	MatchingRules json.RawMessage            `json:"matchingRules"`
+}
+
+type responseDTO struct {
+	Status  int                        `json:"status"`
+	Headers map[string]json.RawMessage `json:"headers"`
+	Body    interface{}                `json:"body"`
+}
+
+type interactionDTO struct {
+	Description    string `json:"description"`
+	ProviderState  string `json:"providerState"`
+	ProviderStates []struct {
+		Name string `json:"name"`
+	} `json:"providerStates"`
+	Request  requestDTO  `json:"request"`
+	Response responseDTO `json:"response"`
+}
+
+type pactDTO struct {
+	Consumer     participantDTO   `json:"consumer"`
+	Provider     participantDTO   `json:"provider"`
+	Interactions []interactionDTO `json:"interactions"`
+}
+
+// Parse decodes a version 2 or version 3 Pact JSON file.
+func Parse(b []byte) (*Pact, error) {
+	var dto pactDTO
+	if err := json.Unmarshal(b, &dto); err != nil {
+		return nil, err
+	}
+
+	p := &Pact{
+		Consumer:     dto.Consumer.Name,
+		Provider:     dto.Provider.Name,
+		Interactions: make([]Interaction, len(dto.Interactions)),
+	}
+	for i, in := range dto.Interactions {
+		out, err := parseInteraction(in)
+		if err != nil {
+			return nil, fmt.Errorf("interactions[%d]: %v", i, err)
+		}
+		p.Interactions[i] = out
+	}
+	return p, nil
+}
+
+func parseInteraction(dto interactionDTO) (Interaction, error) {
+	out := Interaction{
+		Description: dto.Description,
+		Request: Request{
+			Method: strings.ToUpper(dto.Request.Method),
+			Path:   dto.Request.Path,
+			Body:   dto.Request.Body,
+		},
+		Response: Response{
+			Status: dto.Response.Status,
+			Body:   dto.Response.Body,
+		},
+	}
+	if dto.ProviderState != "" {
+		out.ProviderStates = []string{dto.ProviderState}
+	}
+	for _, s := range dto.ProviderStates {
+		out.ProviderStates = append(out.ProviderStates, s.Name)
+	}
+
+	var err error
+	if out.Request.Query, err = parseQuery(dto.Request.Query); err != nil {
+		return out, fmt.Errorf("request.query: %v", err)
+	}
+	if out.Request.Headers, err = parseHeaders(dto.Request.Headers); err != nil {
+		return out, fmt.Errorf("request.headers: %v", err)
+	}
+	if out.Request.Rules, err = parseRules(dto.Request.MatchingRules); err != nil {
+		return out, fmt.Errorf("request.matchingRules: %v", err)
+	}
+	if out.Response.Headers, err = parseHeaders(dto.Response.Headers); err != nil {
+		return out, fmt.Errorf("response.headers: %v", err)
+	}
+	if out.Response.Status == 0 {
+		out.Response.Status = http.StatusOK
+	}
+	return out, nil
+}
+
+// parseQuery decodes either the version 2 query string or the version 3
+// object of query parameter arrays.
+func parseQuery(raw json.RawMessage) (url.Values, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return url.ParseQuery(s)
+	}
+
+	var vs url.Values
+	if err := json.Unmarshal(raw, &vs); err != nil {
+		return nil, err
+	}
+	return vs, nil
+}
+
+// parseHeaders decodes header values given as either a string or, in
+// version 3 files, an array of strings.
+func parseHeaders(hs map[string]json.RawMessage) (http.Header, error) {
+	if hs == nil {
+		return nil, nil
+	}
+
+	out := make(http.Header, len(hs))
+	for k, raw := range hs {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			out[k] = []string{s}
+			continue
+		}
+		var ss []string
+		if err := json.Unmarshal(raw, &ss); err != nil {
+			return nil, fmt.Errorf("%s: %v", k, err)
+		}
+		out[k] = ss
+	}
+	return out, nil
+}
+
+type ruleV3DTO struct {
+	Matchers []Matcher `json:"matchers"`
+}
+
+// parseRules decodes matching rules from either the flat version 2 format or
+// the nested version 3 format, normalising the latter to version 2 locations.
+func parseRules(raw json.RawMessage) (Rules, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &top); err != nil {
+		return nil, err
+	}
+
+	rules := make(Rules)
+	for key, b := range top {
+		// version 2 keys are JSON paths of a single matcher
+		if strings.HasPrefix(key, "$") {
+			var m Matcher
+			if err := json.Unmarshal(b, &m); err != nil {
+				return nil, fmt.Errorf("%s: %v", key, err)
+			}
+			if m.Match == "" && m.Regex != "" {
+				m.Match = "regex"
+			}
+			rules[key] = append(rules[key], m)
+			continue
+		}
+
+		switch key {
+		case "path":
+			var r ruleV3DTO
+			if err := json.Unmarshal(b, &r); err != nil {
+				return nil, fmt.Errorf("%s: %v", key, err)
+			}
+			rules["$.path"] = r.Matchers
+
+		case "query", "header", "body":
+			var rs map[string]ruleV3DTO
+			if err := json.Unmarshal(b, &rs); err != nil {
+				return nil, fmt.Errorf("%s: %v", key, err)
+			}
+			for name, r := range rs {
+				switch key {
+				case "query":
+					rules["$.query."+name] = r.Matchers
+				case "header":
+					rules["$.headers."+name] = r.Matchers
+				default:
+					rules["$.body"+strings.TrimPrefix(name, "$")] = r.Matchers
+				}
+			}
+
+		default:
+			return nil, fmt.Errorf("unsupported matching rule category: %s", key)
+		}
+	}
+	return rules, nil
+}
+
+// lookup returns the Matchers applying to the value at the given location,
+// either directly or inherited from its closest ancestor with rules.
+func (rs Rules) lookup(location string) ([]Matcher, bool) {
+	keys := make([]string, 0, len(rs))
+	for key := range rs {
+		keys = append(keys, key)
+	}
+	// prefer the most specific rule location
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+
+	for _, key := range keys {
+		if covers(key, location) {
+			return rs[key], true
+		}
+	}
+	return nil, false
+}
+
+// lookupHeader is the case-insensitive form of lookup for a header name.
+func (rs Rules) lookupHeader(name string) ([]Matcher, bool) {
+	for key, ms := range rs {
+		if strings.EqualFold(key, "$.headers."+name) {
+			return ms, true
+		}
+	}
+	return nil, false
+}
+
+// covers reports whether the rule at location pattern, which may contain
+// "[*]" and ".*" wildcards, applies to the concrete location or its parent.
+func covers(pattern, location string) bool {
+	for pattern != "" {
+		switch {
+		case strings.HasPrefix(pattern, "[*]"):
+			if !strings.HasPrefix(location, "[") {
+				return false
+			}
+			end := strings.Index(location, "]")
+			pattern, location = pattern[3:], location[end+1:]
+
+		case strings.HasPrefix(pattern, ".*"):
+			if !strings.HasPrefix(location, ".") && !strings.HasPrefix(location, "[") {
+				return false
+			}
+			end := strings.IndexAny(location[1:], ".[")
+			if end < 0 {
+				end = len(location) - 1
+			}
+			pattern, location = pattern[2:], location[end+1:]
+
+		default:
+			if location == "" || pattern[0] != location[0] {
+				return false
+			}
+			pattern, location = pattern[1:], location[1:]
+		}
+	}
+	return location == "" || location[0] == '.' || location[0] == '['
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package pact_test
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+	"github.com/senseyeio/mbgo/pact"
+)
+
+const pactV2 = `{
+	"consumer": {"name": "web"},
+	"provider": {"name": "orders"},
+	"interactions": [
+		{
+			"description": "a request for an order",
+			"providerState": "order 1 exists",
+			"request": {
+				"method": "get",
+				"path": "/orders/1",
+				"query": "expand=items",
+				"headers": {"Accept": "application/json"}
+			},
+			"response": {
+				"status": 200,
+				"headers": {"Content-Type": "application/json"},
+				"body": {"id": 1}
+			}
+		},
+		{
+			"description": "a request to create an order",
+			"request": {
+				"method": "POST",
+				"path": "/orders",
+				"body": {"name": "widget", "quantity": 2},
+				"matchingRules": {
+					"$.body.name": {"match": "regex", "regex": "[a-z]+"},
+					"$.body.quantity": {"match": "type"}
+				}
+			},
+			"response": {"status": 201}
+		}
+	],
+	"metadata": {"pactSpecification": {"version": "2.0.0"}}
+}`
+
+const pactV3 = `{
+	"consumer": {"name": "web"},
+	"provider": {"name": "orders"},
+	"interactions": [
+		{
+			"description": "a search for orders",
+			"providerStates": [{"name": "orders exist"}],
+			"request": {
+				"method": "GET",
+				"path": "/orders/search",
+				"query": {"q": ["widget"]},
+				"headers": {"Accept": ["application/json"]},
+				"matchingRules": {
+					"path": {"matchers": [{"match": "regex", "regex": "/orders/[a-z]+"}]},
+					"header": {"Accept": {"matchers": [{"match": "include", "value": "json"}]}}
+				}
+			},
+			"response": {"status": 200, "body": []}
+		}
+	],
+	"metadata": {"pactSpecification": {"version": "3.0.0"}}
+}`
+
+func TestParse(t *testing.T) {
+	p, err := pact.Parse([]byte(pactV3))
+	assert.MustOk(t, err)
+
+	assert.Equals(t, &pact.Pact{
+		Consumer: "web",
+		Provider: "orders",
+		Interactions: []pact.Interaction{
+			{
+				Description:    "a search for orders",
+				ProviderStates: []string{"orders exist"},
+				Request: pact.Request{
+					Method:  http.MethodGet,
+					Path:    "/orders/search",
+					Query:   url.Values{"q": {"widget"}},
+					Headers: http.Header{"Accept": {"application/json"}},
+					Rules: pact.Rules{
+						"$.path":           {{Match: "regex", Regex: "/orders/[a-z]+"}},
+						"$.headers.Accept": {{Match: "include", Value: "json"}},
+					},
+				},
+				Response: pact.Response{
+					Status: http.StatusOK,
+					Body:   []interface{}{},
+				},
+			},
+		},
+	}, p)
+}
+
+func TestPact_Imposter(t *testing.T) {
+	p, err := pact.Parse([]byte(pactV2))
+	assert.MustOk(t, err)
+
+	imp := p.Imposter(8080)
+	assert.Equals(t, "web-orders", imp.Name)
+	assert.Equals(t, true, imp.RecordRequests)
+
+	assert.Equals(t, []mbgo.Stub{
+		{
+			Predicates: []mbgo.Predicate{
+				{
+					Operator: "equals",
+					Request:  mbgo.HTTPRequest{Method: http.MethodGet},
+				},
+				{
+					Operator:      "equals",
+					Request:       mbgo.HTTPRequest{Path: "/orders/1"},
+					CaseSensitive: true,
+				},
+				{
+					Operator:      "equals",
+					Request:       mbgo.HTTPRequest{Query: url.Values{"expand": {"items"}}},
+					CaseSensitive: true,
+				},
+				{
+					Operator: "equals",
+					Request:  mbgo.HTTPRequest{Headers: http.Header{"Accept": {"application/json"}}},
+				},
+			},
+			Responses: []mbgo.Response{
+				{
+					Type: "is",
+					Value: mbgo.HTTPResponse{
+						StatusCode: http.StatusOK,
+						Headers:    http.Header{"Content-Type": {"application/json"}},
+						Body:       map[string]interface{}{"id": float64(1)},
+					},
+				},
+			},
+		},
+		{
+			Predicates: []mbgo.Predicate{
+				{
+					Operator: "equals",
+					Request:  mbgo.HTTPRequest{Method: http.MethodPost},
+				},
+				{
+					Operator:      "equals",
+					Request:       mbgo.HTTPRequest{Path: "/orders"},
+					CaseSensitive: true,
+				},
+				{
+					Operator:      "matches",
+					Request:       mbgo.HTTPRequest{Body: "^(?:[a-z]+)$"},
+					JSONPath:      &mbgo.JSONPath{Selector: "$.name"},
+					CaseSensitive: true,
+				},
+				{
+					Operator: "matches",
+					Request:  mbgo.HTTPRequest{Body: ".*"},
+					JSONPath: &mbgo.JSONPath{Selector: "$.quantity"},
+				},
+			},
+			Responses: []mbgo.Response{
+				{
+					Type:  "is",
+					Value: mbgo.HTTPResponse{StatusCode: http.StatusCreated},
+				},
+			},
+		},
+	}, imp.Stubs)
+}
+
+func TestPact_Verify(t *testing.T) {
+	p, err := pact.Parse([]byte(pactV2))
+	assert.MustOk(t, err)
+
+	get := &mbgo.HTTPRequest{
+		Method:  http.MethodGet,
+		Path:    "/orders/1",
+		Query:   url.Values{"expand": {"items"}, "page": {"2"}},
+		Headers: http.Header{"Accept": {"application/json"}},
+	}
+
+	t.Run("should report interactions without a matching recorded request", func(t *testing.T) {
+		err := p.Verify(mbgo.Imposter{
+			Requests: []interface{}{
+				get,
+				&mbgo.HTTPRequest{
+					Method: http.MethodPost,
+					Path:   "/orders",
+					Body:   `{"name":"Widget","quantity":3}`,
+				},
+			},
+		})
+
+		var ve *pact.VerificationError
+		assert.Equals(t, true, errors.As(err, &ve))
+		assert.Equals(t, []string{"a request to create an order"}, ve.Unmatched)
+	})
+
+	t.Run("should succeed when every interaction is matched", func(t *testing.T) {
+		err := p.Verify(mbgo.Imposter{
+			Requests: []interface{}{
+				get,
+				&mbgo.HTTPRequest{
+					Method: http.MethodPost,
+					Path:   "/orders",
+					Body:   `{"name":"gadget","quantity":3}`,
+				},
+			},
+		})
+		assert.Ok(t, err)
+	})
+	t.Run("should match recorded headers case-insensitively", func(t *testing.T) {
+		assert.Equals(t, true, p.Interactions[0].Request.Matches(mbgo.HTTPRequest{
+			Method:  http.MethodGet,
+			Path:    "/orders/1",
+			Query:   url.Values{"expand": {"items"}},
+			Headers: http.Header{"accept": {"application/json"}},
+		}))
+	})
+}
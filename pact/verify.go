@@ -0,0 +1,261 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package pact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/senseyeio/mbgo"
+)
+
+// VerificationError is returned by Verify when some Interactions have no
+// matching recorded request.
+type VerificationError struct {
+	// Unmatched lists the descriptions of the unmatched Interactions.
+	Unmatched []string
+}
+
+// Error satisfies the error interface.
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("pact: %d interaction(s) not satisfied: %s",
+		len(e.Unmatched), strings.Join(e.Unmatched, "; "))
+}
+
+// Verify checks that every Interaction of the Pact is satisfied by at least
+// one of the requests recorded by imp, using the same matching semantics as
+// the Stubs built by Interaction.Stub. The Imposter should be retrieved from
+// mountebank after the consumer tests have run, and must record requests.
+func (p *Pact) Verify(imp mbgo.Imposter) error {
+	reqs := make([]mbgo.HTTPRequest, 0, len(imp.Requests))
+	for _, r := range imp.Requests {
+		switch t := r.(type) {
+		case mbgo.HTTPRequest:
+			reqs = append(reqs, t)
+		case *mbgo.HTTPRequest:
+			reqs = append(reqs, *t)
+		}
+	}
+
+	var unmatched []string
+	for _, in := range p.Interactions {
+		ok := false
+		for _, r := range reqs {
+			if in.Request.Matches(r) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			unmatched = append(unmatched, in.Description)
+		}
+	}
+
+	if len(unmatched) > 0 {
+		return &VerificationError{Unmatched: unmatched}
+	}
+	return nil
+}
+
+// Matches reports whether the recorded request r satisfies the Request.
+func (req Request) Matches(r mbgo.HTTPRequest) bool {
+	if req.Method != "" && !strings.EqualFold(req.Method, r.Method) {
+		return false
+	}
+	if req.Path != "" && !matchString(req.Rules["$.path"], req.Path, r.Path) {
+		return false
+	}
+
+	for k, vs := range req.Query {
+		actual, ok := r.Query[k]
+		if !ok || len(actual) < len(vs) {
+			return false
+		}
+		for i, v := range vs {
+			if !matchString(req.Rules["$.query."+k], v, actual[i]) {
+				return false
+			}
+		}
+	}
+
+	for k, vs := range req.Headers {
+		actual := headerValue(r.Headers, k)
+		if actual == "" {
+			return false
+		}
+		ms, _ := req.Rules.lookupHeader(k)
+		if !matchString(ms, strings.Join(vs, ", "), actual) {
+			return false
+		}
+	}
+
+	if req.Body == nil {
+		return true
+	}
+	body, ok := decodeBody(r.Body)
+	if !ok {
+		return false
+	}
+	return matchBody(req, body)
+}
+
+// headerValue returns the first value of the named header, matching the name
+// case-insensitively as recorded headers keep the case sent by the client.
+func headerValue(h http.Header, name string) string {
+	for k, vs := range h {
+		if strings.EqualFold(k, name) && len(vs) > 0 {
+			return vs[0]
+		}
+	}
+	return ""
+}
+
+func matchString(ms []Matcher, expected, actual string) bool {
+	if len(ms) == 0 {
+		return expected == actual
+	}
+	for _, m := range ms {
+		switch m.Match {
+		case "regex":
+			re, err := regexp.Compile(anchor(m.Regex))
+			if err != nil || !re.MatchString(actual) {
+				return false
+			}
+		case "include":
+			if !strings.Contains(actual, m.Value) {
+				return false
+			}
+		case "equality":
+			if expected != actual {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// decodeBody returns the JSON value of a recorded body, which mountebank
+// records as a string unless it was sent as a JSON object.
+func decodeBody(body interface{}) (interface{}, bool) {
+	s, ok := body.(string)
+	if !ok {
+		return body, body != nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		// not JSON, so compare the raw text
+		return s, true
+	}
+	return v, true
+}
+
+func matchBody(req Request, actual interface{}) bool {
+	hasRules := false
+	for key := range req.Rules {
+		if covers("$.body", key) {
+			hasRules = true
+			break
+		}
+	}
+	if !hasRules {
+		return reflect.DeepEqual(normalise(req.Body), normalise(actual))
+	}
+
+	for _, l := range leaves("$.body", req.Body) {
+		v, ok := lookup(actual, l.location[len("$.body"):])
+		if !ok {
+			return false
+		}
+		ms, ok := req.Rules.lookup(l.location)
+		if !ok {
+			if !reflect.DeepEqual(normalise(l.value), normalise(v)) {
+				return false
+			}
+			continue
+		}
+		for _, m := range ms {
+			switch m.Match {
+			case "regex", "include", "equality":
+				if !matchString([]Matcher{m}, jsonText(l.value), jsonText(v)) {
+					return false
+				}
+			default:
+				if reflect.TypeOf(l.value) != reflect.TypeOf(v) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// normalise round trips v through JSON so that equal values share the same
+// Go representation.
+func normalise(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// lookup resolves a concrete location produced by leaves, relative to the
+// root of v, e.g. ".items[0].name" or `["content-type"]`.
+func lookup(v interface{}, location string) (interface{}, bool) {
+	for location != "" {
+		switch location[0] {
+		case '.':
+			end := strings.IndexAny(location[1:], ".[")
+			if end < 0 {
+				end = len(location) - 1
+			}
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			v, ok = m[location[1:end+1]]
+			if !ok {
+				return nil, false
+			}
+			location = location[end+1:]
+
+		case '[':
+			end := strings.Index(location, "]")
+			if end < 0 {
+				return nil, false
+			}
+			index := location[1:end]
+			if key, err := strconv.Unquote(index); err == nil {
+				m, ok := v.(map[string]interface{})
+				if !ok {
+					return nil, false
+				}
+				if v, ok = m[key]; !ok {
+					return nil, false
+				}
+			} else {
+				i, err := strconv.Atoi(index)
+				a, ok := v.([]interface{})
+				if err != nil || !ok || i >= len(a) {
+					return nil, false
+				}
+				v = a[i]
+			}
+			location = location[end+1:]
+
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
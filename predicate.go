@@ -0,0 +1,84 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+// The supported Predicate operators.
+//
+// See more information about them at:
+// http://www.mbtest.org/docs/api/predicates.
+const (
+	OpEquals     = "equals"
+	OpDeepEquals = "deepEquals"
+	OpContains   = "contains"
+	OpStartsWith = "startsWith"
+	OpEndsWith   = "endsWith"
+	OpMatches    = "matches"
+	OpExists     = "exists"
+	OpNot        = "not"
+	OpAnd        = "and"
+	OpOr         = "or"
+	OpInject     = "inject"
+)
+
+// Equals returns a Predicate matching requests whose fields equal those of
+// req, which is typically an HTTPRequest or a TCPRequest.
+func Equals(req interface{}) Predicate {
+	return Predicate{Operator: OpEquals, Request: req}
+}
+
+// DeepEquals returns a Predicate matching requests whose fields equal those
+// of req, including any object fields such as the query, in their entirety.
+func DeepEquals(req interface{}) Predicate {
+	return Predicate{Operator: OpDeepEquals, Request: req}
+}
+
+// Contains returns a Predicate matching requests whose fields contain those of req.
+func Contains(req interface{}) Predicate {
+	return Predicate{Operator: OpContains, Request: req}
+}
+
+// StartsWith returns a Predicate matching requests whose fields start with those of req.
+func StartsWith(req interface{}) Predicate {
+	return Predicate{Operator: OpStartsWith, Request: req}
+}
+
+// EndsWith returns a Predicate matching requests whose fields end with those of req.
+func EndsWith(req interface{}) Predicate {
+	return Predicate{Operator: OpEndsWith, Request: req}
+}
+
+// Matches returns a Predicate matching requests whose fields match the
+// regular expressions given by the fields of req.
+func Matches(req interface{}) Predicate {
+	return Predicate{Operator: OpMatches, Request: req}
+}
+
+// Exists returns a Predicate matching requests in which each of the given
+// fields, such as "body", exists if true or does not exist if false. Use an
+// HTTPExistsRequest to test for the query parameters or headers of an "http"
+// request.
+func Exists(fields map[string]bool) Predicate {
+	return Predicate{Operator: OpExists, Request: fields}
+}
+
+// Not returns a Predicate matching requests not matched by p.
+func Not(p Predicate) Predicate {
+	return Predicate{Operator: OpNot, Request: p}
+}
+
+// And returns a Predicate matching requests matched by all of ps.
+func And(ps ...Predicate) Predicate {
+	return Predicate{Operator: OpAnd, Request: ps}
+}
+
+// Or returns a Predicate matching requests matched by any of ps.
+func Or(ps ...Predicate) Predicate {
+	return Predicate{Operator: OpOr, Request: ps}
+}
+
+// Inject returns a Predicate matching requests for which the JavaScript
+// function js returns true.
+func Inject(js string) Predicate {
+	return Predicate{Operator: OpInject, Request: js}
+}
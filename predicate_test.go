@@ -0,0 +1,139 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+)
+
+func TestPredicateConstructors(t *testing.T) {
+	get := mbgo.HTTPRequest{Method: http.MethodGet}
+
+	p := mbgo.And(
+		mbgo.Equals(get),
+		mbgo.Not(mbgo.Exists(map[string]bool{"body": true})),
+		mbgo.Or(mbgo.StartsWith(mbgo.HTTPRequest{Path: "/foo"}), mbgo.Inject("config => true")),
+	)
+	assert.Equals(t, mbgo.Predicate{
+		Operator: mbgo.OpAnd,
+		Request: []mbgo.Predicate{
+			{Operator: mbgo.OpEquals, Request: get},
+			{Operator: mbgo.OpNot, Request: mbgo.Predicate{Operator: mbgo.OpExists, Request: map[string]bool{"body": true}}},
+			{Operator: mbgo.OpOr, Request: []mbgo.Predicate{
+				{Operator: mbgo.OpStartsWith, Request: mbgo.HTTPRequest{Path: "/foo"}},
+				{Operator: mbgo.OpInject, Request: "config => true"},
+			}},
+		},
+	}, p)
+	assert.Ok(t, p.Validate())
+
+	b, err := json.Marshal(p)
+	assert.MustOk(t, err)
+	assert.Equals(t, `{"and":[{"equals":{"method":"GET"}},{"not":{"exists":{"body":true}}},`+
+		`{"or":[{"startsWith":{"path":"/foo"}},{"inject":"config =\u003e true"}]}]}`, string(b))
+}
+
+func TestPredicate_MarshalJSON_Not(t *testing.T) {
+	inner := mbgo.Equals(mbgo.HTTPRequest{Path: "/foo"})
+
+	for _, req := range []interface{}{inner, &inner} {
+		b, err := json.Marshal(mbgo.Predicate{Operator: mbgo.OpNot, Request: req})
+		assert.MustOk(t, err)
+		assert.Equals(t, `{"not":{"equals":{"path":"/foo"}}}`, string(b))
+	}
+
+	_, err := mbgo.Predicate{Operator: mbgo.OpNot}.MarshalJSON()
+	assert.Equals(t, errors.New("predicate request is required"), err)
+}
+
+func TestHTTPExistsRequest_JSON(t *testing.T) {
+	yes, no := true, false
+	imp := mbgo.Imposter{
+		Port:  8080,
+		Proto: "http",
+		Stubs: []mbgo.Stub{
+			{
+				Predicates: []mbgo.Predicate{
+					{Operator: mbgo.OpExists, Request: &mbgo.HTTPExistsRequest{
+						Body:    &no,
+						Query:   map[string]bool{"q": true},
+						Headers: map[string]bool{"X-Api-Key": true},
+					}},
+					mbgo.Not(mbgo.Predicate{Operator: mbgo.OpExists, Request: &mbgo.HTTPExistsRequest{Method: &yes}}),
+				},
+				Responses: []mbgo.Response{
+					{Type: "is", Value: mbgo.HTTPResponse{StatusCode: http.StatusOK}},
+				},
+			},
+		},
+	}
+	assert.Ok(t, imp.Validate())
+
+	b, err := json.Marshal(imp)
+	assert.MustOk(t, err)
+	assert.Equals(t, `{"protocol":"http","port":8080,"stubs":[{"predicates":[`+
+		`{"exists":{"body":false,"query":{"q":true},"headers":{"X-Api-Key":true}}},`+
+		`{"not":{"exists":{"method":true}}}],"responses":[{"is":{"statusCode":200}}]}]}`, string(b))
+
+	var got mbgo.Imposter
+	assert.MustOk(t, json.Unmarshal(b, &got))
+	assert.Equals(t, imp.Stubs[0].Predicates, got.Stubs[0].Predicates)
+}
+
+func TestHTTPExistsRequest_Validate(t *testing.T) {
+	yes := true
+
+	err := mbgo.Imposter{Port: 8080, Proto: "tcp", Stubs: []mbgo.Stub{{
+		Predicates: []mbgo.Predicate{{Operator: mbgo.OpExists, Request: mbgo.HTTPExistsRequest{Body: &yes}}},
+	}}}.Validate()
+	assert.Equals(t, true, err != nil)
+
+	err = mbgo.Equals(mbgo.HTTPExistsRequest{Body: &yes}).Validate()
+	assert.Equals(t, true, err != nil)
+}
+
+func TestHTTPExistsRequest_UnmarshalJSON_Fields(t *testing.T) {
+	cases := []struct {
+		Description string
+		Request     string
+		Expected    interface{}
+	}{
+		{
+			Description: "should keep the fields unknown to HTTPExistsRequest",
+			Request:     `{"ip":true,"method":true}`,
+			Expected: mbgo.Fields{
+				{Name: "ip", Value: true},
+				{Name: "method", Value: true},
+			},
+		},
+		{
+			Description: "should decode the fields of a JSON body",
+			Request:     `{"body":{"a":true}}`,
+			Expected:    mbgo.Fields{{Name: "body", Value: map[string]interface{}{"a": true}}},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.Description, func(t *testing.T) {
+			t.Parallel()
+
+			b := `{"protocol":"http","port":8080,"stubs":[{"predicates":[{"exists":` + c.Request + `}]}]}`
+			var imp mbgo.Imposter
+			assert.MustOk(t, json.Unmarshal([]byte(b), &imp))
+			assert.Equals(t, c.Expected, imp.Stubs[0].Predicates[0].Request)
+
+			out, err := json.Marshal(imp.Stubs[0].Predicates[0])
+			assert.MustOk(t, err)
+			assert.Equals(t, `{"exists":`+c.Request+`}`, string(out))
+		})
+	}
+}
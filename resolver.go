@@ -0,0 +1,53 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// resolverBuffer is the start of the generated resolvers, which converts the
+// received data into a Buffer as it is a string when using ModeText.
+const resolverBuffer = "function (requestData) {\n" +
+	"    var buf = Buffer.isBuffer(requestData) ? requestData : Buffer.from(requestData);\n"
+
+// FixedLengthResolver returns an Imposter.EndOfRequestResolver for requests
+// of exactly n bytes.
+func FixedLengthResolver(n int) string {
+	return resolverBuffer +
+		fmt.Sprintf("    return buf.length >= %d;\n", n) +
+		"}"
+}
+
+// LengthPrefixResolver returns an Imposter.EndOfRequestResolver for requests
+// starting with their length as a big-endian unsigned integer of size bytes,
+// which does not count the prefix itself. The size must be 1, 2 or 4.
+func LengthPrefixResolver(size int) (string, error) {
+	switch size {
+	case 1, 2, 4:
+	default:
+		return "", fmt.Errorf("length prefix size must be 1, 2 or 4, got %d", size)
+	}
+	return resolverBuffer +
+		fmt.Sprintf("    return buf.length >= %d && buf.length >= %d + buf.readUIntBE(0, %d);\n", size, size, size) +
+		"}", nil
+}
+
+// DelimiterResolver returns an Imposter.EndOfRequestResolver for requests
+// terminated by the given delimiter, such as "\r\n".
+func DelimiterResolver(delim []byte) string {
+	// encode the bytes as a JSON array of numbers, rather than the base64
+	// string json.Marshal produces for a []byte
+	ns := make([]int, len(delim))
+	for i, b := range delim {
+		ns[i] = int(b)
+	}
+	arr, _ := json.Marshal(ns)
+
+	return resolverBuffer +
+		fmt.Sprintf("    var delim = Buffer.from(%s);\n", arr) +
+		"    return buf.length >= delim.length && buf.slice(buf.length - delim.length).equals(delim);\n" +
+		"}"
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+)
+
+func TestResolvers(t *testing.T) {
+	assert.Equals(t, `function (requestData) {
+    var buf = Buffer.isBuffer(requestData) ? requestData : Buffer.from(requestData);
+    return buf.length >= 8;
+}`, mbgo.FixedLengthResolver(8))
+
+	js, err := mbgo.LengthPrefixResolver(2)
+	assert.MustOk(t, err)
+	assert.Equals(t, `function (requestData) {
+    var buf = Buffer.isBuffer(requestData) ? requestData : Buffer.from(requestData);
+    return buf.length >= 2 && buf.length >= 2 + buf.readUIntBE(0, 2);
+}`, js)
+
+	_, err = mbgo.LengthPrefixResolver(3)
+	assert.Equals(t, errors.New("length prefix size must be 1, 2 or 4, got 3"), err)
+
+	assert.Equals(t, `function (requestData) {
+    var buf = Buffer.isBuffer(requestData) ? requestData : Buffer.from(requestData);
+    var delim = Buffer.from([13,10]);
+    return buf.length >= delim.length && buf.slice(buf.length - delim.length).equals(delim);
+}`, mbgo.DelimiterResolver([]byte("\r\n")))
+}
+
+func TestImposter_JSON_EndOfRequestResolver(t *testing.T) {
+	imp := mbgo.Imposter{
+		Port:                 502,
+		Proto:                "tcp",
+		Mode:                 mbgo.ModeBinary,
+		EndOfRequestResolver: mbgo.FixedLengthResolver(8),
+	}
+
+	b, err := json.Marshal(imp)
+	assert.MustOk(t, err)
+
+	var dto struct {
+		Resolver map[string]string `json:"endOfRequestResolver"`
+	}
+	assert.MustOk(t, json.Unmarshal(b, &dto))
+	assert.Equals(t, map[string]string{"inject": imp.EndOfRequestResolver}, dto.Resolver)
+
+	var got mbgo.Imposter
+	assert.MustOk(t, json.Unmarshal(b, &got))
+	assert.Equals(t, imp, got)
+
+	err = mbgo.Imposter{Port: 8080, Proto: "http", EndOfRequestResolver: "function () {}"}.Validate()
+	assert.Equals(t, &mbgo.ValidationError{
+		Errors: []mbgo.FieldError{
+			{Path: "/endOfRequestResolver", Reason: "endOfRequestResolver can only be used with the tcp protocol"},
+		},
+	}, err)
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// scenarioPath is the path of the control stubs added for each Scenario,
+// which are used by Scenario.State and Scenario.Reset.
+const scenarioPath = "/__mbgo/scenarios/"
+
+// ScenarioStep is a Stub which only matches while its Scenario is in a given
+// state, and which may move the Scenario into another state once matched.
+type ScenarioStep struct {
+	// State is the state of the Scenario in which the step matches, or
+	// any state if empty.
+	State string
+
+	// Predicates are the additional Predicates a request must match.
+	Predicates []Predicate
+
+	// Response is the response sent to a matched request.
+	Response HTTPResponse
+
+	// Next is the state the Scenario moves into once the step is matched,
+	// or the state is unchanged if empty.
+	Next string
+}
+
+// ScenarioStubs compiles the steps of the Scenario with the given name and
+// initial state into Stubs for an "http" Imposter, in order. The state of
+// the Scenario is kept in the state shared by the injections of the Imposter,
+// which therefore requires mountebank to be started using --allowInjection.
+//
+// The first two Stubs are control stubs, matching the GET and DELETE requests
+// sent by Scenario.State and Scenario.Reset respectively. They must precede
+// any other Stub matching those requests, such as a catch-all Stub.
+func ScenarioStubs(name, initial string, steps []ScenarioStep) ([]Stub, error) {
+	path := scenarioPath + url.PathEscape(name)
+	control := func(method string) []Predicate {
+		return []Predicate{Equals(HTTPRequest{Method: method, Path: path})}
+	}
+
+	stubs := []Stub{
+		{
+			Predicates: control(http.MethodGet),
+			Responses: []Response{scenarioInject(name, initial,
+				`return {statusCode: 200, headers: {"Content-Type": "application/json"}, body: {state: states[name]}};`)},
+		},
+		{
+			Predicates: control(http.MethodDelete),
+			Responses: []Response{scenarioInject(name, initial,
+				fmt.Sprintf("states[name] = %s;\n    return {statusCode: 204};", jsString(initial)))},
+		},
+	}
+
+	for _, step := range steps {
+		stub := Stub{Predicates: append([]Predicate(nil), step.Predicates...)}
+		if step.State != "" {
+			stub.Predicates = append(stub.Predicates, Inject(scenarioJS(name, initial,
+				fmt.Sprintf("return states[name] === %s;", jsString(step.State)))))
+		}
+
+		if step.Next == "" {
+			stub.Responses = []Response{{Type: "is", Value: step.Response}}
+		} else {
+			b, err := json.Marshal(step.Response)
+			if err != nil {
+				return nil, err
+			}
+			stub.Responses = []Response{scenarioInject(name, initial,
+				fmt.Sprintf("states[name] = %s;\n    return %s;", jsString(step.Next), b))}
+		}
+		stubs = append(stubs, stub)
+	}
+	return stubs, nil
+}
+
+// scenarioJS returns a JavaScript injection function running body with the
+// variables name, the name of the Scenario, and states, its state by name.
+func scenarioJS(name, initial, body string) string {
+	return "function (config) {\n" +
+		fmt.Sprintf("    var name = %s;\n", jsString(name)) +
+		"    var states = config.state.mbgoScenarios = config.state.mbgoScenarios || {};\n" +
+		fmt.Sprintf("    if (!states.hasOwnProperty(name)) { states[name] = %s; }\n", jsString(initial)) +
+		"    " + body + "\n" +
+		"}"
+}
+
+func scenarioInject(name, initial, body string) Response {
+	js, _ := json.Marshal(scenarioJS(name, initial, body))
+	return Response{Type: "inject", Value: json.RawMessage(js)}
+}
+
+// jsString returns s as a JavaScript string literal.
+func jsString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// Scenario is a handle on the Scenario with the given name, whose stubs are
+// compiled by ScenarioStubs, on an "http" or "https" Imposter.
+//
+// Scenario.State and Scenario.Reset send their requests directly to the
+// control stubs of the Imposter rather than to the mountebank API, so they
+// are not affected by the options of the Client. As with any other request,
+// they are recorded by an Imposter using RecordRequests.
+type Scenario struct {
+	// URL is the base URL of the Imposter, of which only the scheme and host
+	// are used. It defaults to the "http" scheme, the host of the mountebank
+	// API and the port of the Imposter, and must be set if the Imposter uses
+	// "https" or is reached on another host.
+	URL *url.URL
+
+	// HTTPClient sends the requests to the Imposter, or http.DefaultClient
+	// if nil.
+	HTTPClient *http.Client
+
+	port int
+	name string
+}
+
+// Scenario returns a handle on the Scenario with the given name on the
+// Imposter on the given port, such as one created with the Stubs returned
+// by ScenarioStubs.
+func (cli *Client) Scenario(port int, name string) *Scenario {
+	return &Scenario{
+		URL: &url.URL{
+			Scheme: "http",
+			Host:   net.JoinHostPort(cli.root.Hostname(), strconv.Itoa(port)),
+		},
+		port: port,
+		name: name,
+	}
+}
+
+// AddScenario adds the Stubs compiled from the steps of the Scenario with the
+// given name and initial state by ScenarioStubs to the Imposter on the given
+// port, returning a handle on the Scenario. The control stubs are inserted
+// before the existing Stubs, so that none of them can match their requests,
+// and the other Stubs are appended. The Stubs already added are removed if
+// adding one of them fails, and the call is serialised with other stub
+// changes made by the Client to the same Imposter.
+func (cli *Client) AddScenario(ctx context.Context, port int, name, initial string, steps []ScenarioStep) (*Scenario, error) {
+	ctx = withOperation(ctx, Operation{Name: "AddScenario", Port: port})
+
+	stubs, err := ScenarioStubs(name, initial, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cli.lockStubs(port)()
+
+	var added []int
+	for i, stub := range stubs {
+		index := -1
+		if i < 2 {
+			index = i
+		}
+		imp, err := cli.addStub(ctx, port, index, stub)
+		if err != nil {
+			// remove the last stubs first so that the other indexes hold
+			for j := len(added) - 1; j >= 0; j-- {
+				_, _ = cli.removeStub(ctx, port, added[j])
+			}
+			return nil, err
+		}
+		if index < 0 {
+			index = len(imp.Stubs) - 1
+		}
+		added = append(added, index)
+	}
+	return cli.Scenario(port, name), nil
+}
+
+// ScenarioNotFoundError is returned by Scenario.State and Scenario.Reset when
+// the control stubs of the Scenario do not respond.
+type ScenarioNotFoundError struct {
+	Port int
+	Name string
+}
+
+// Error satisfies the error interface.
+func (e *ScenarioNotFoundError) Error() string {
+	return fmt.Sprintf("no scenario named %q in imposter %d", e.Name, e.Port)
+}
+
+// State returns the current state of the Scenario, which is requested from
+// its Imposter.
+//
+// A *ScenarioNotFoundError is returned if the Imposter has no such Scenario.
+func (s *Scenario) State(ctx context.Context) (string, error) {
+	resp, err := s.do(ctx, http.MethodGet)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var dto struct {
+		State *string `json:"state"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", &ScenarioNotFoundError{Port: s.port, Name: s.name}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&dto); err != nil || dto.State == nil {
+		// the default response of the Imposter is sent if no stub matches
+		return "", &ScenarioNotFoundError{Port: s.port, Name: s.name}
+	}
+	return *dto.State, nil
+}
+
+// Reset moves the Scenario back into its initial state, by sending a request
+// to its Imposter.
+//
+// A *ScenarioNotFoundError is returned if the Imposter has no such Scenario.
+func (s *Scenario) Reset(ctx context.Context) error {
+	resp, err := s.do(ctx, http.MethodDelete)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return &ScenarioNotFoundError{Port: s.port, Name: s.name}
+	}
+	return nil
+}
+
+// do sends a request to the control stub of the Scenario matching method.
+func (s *Scenario) do(ctx context.Context, method string) (*http.Response, error) {
+	u := url.URL{
+		Scheme:  s.URL.Scheme,
+		Host:    s.URL.Host,
+		Path:    scenarioPath + s.name,
+		RawPath: scenarioPath + url.PathEscape(s.name),
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cli := s.HTTPClient
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	return cli.Do(req)
+}
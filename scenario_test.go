@@ -0,0 +1,186 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+)
+
+func TestScenarioStubs(t *testing.T) {
+	stubs, err := mbgo.ScenarioStubs("order", "Started", []mbgo.ScenarioStep{
+		{
+			State:      "Started",
+			Predicates: []mbgo.Predicate{mbgo.Equals(mbgo.HTTPRequest{Method: http.MethodGet, Path: "/order"})},
+			Response:   mbgo.HTTPResponse{StatusCode: http.StatusNotFound},
+		},
+		{
+			Predicates: []mbgo.Predicate{mbgo.Equals(mbgo.HTTPRequest{Method: http.MethodPost, Path: "/order"})},
+			Response:   mbgo.HTTPResponse{StatusCode: http.StatusCreated},
+			Next:       "Ordered",
+		},
+	})
+	assert.MustOk(t, err)
+	assert.Ok(t, mbgo.Imposter{Port: 8080, Proto: "http", Stubs: stubs}.Validate())
+
+	assert.Equals(t, 4, len(stubs))
+	assert.Equals(t, mbgo.Equals(mbgo.HTTPRequest{Method: http.MethodGet, Path: "/__mbgo/scenarios/order"}), stubs[0].Predicates[0])
+	assert.Equals(t, mbgo.Equals(mbgo.HTTPRequest{Method: http.MethodDelete, Path: "/__mbgo/scenarios/order"}), stubs[1].Predicates[0])
+
+	// a step in a given state gets an inject predicate testing the state
+	assert.Equals(t, 2, len(stubs[2].Predicates))
+	assert.Equals(t, mbgo.OpInject, stubs[2].Predicates[1].Operator)
+	assert.Equals(t, "is", stubs[2].Responses[0].Type)
+
+	// a step moving into another state gets an inject response
+	assert.Equals(t, 1, len(stubs[3].Predicates))
+	assert.Equals(t, "inject", stubs[3].Responses[0].Type)
+	var js string
+	assert.MustOk(t, json.Unmarshal(stubs[3].Responses[0].Value.(json.RawMessage), &js))
+	assert.Equals(t, "function (config) {\n"+
+		"    var name = \"order\";\n"+
+		"    var states = config.state.mbgoScenarios = config.state.mbgoScenarios || {};\n"+
+		"    if (!states.hasOwnProperty(name)) { states[name] = \"Started\"; }\n"+
+		"    states[name] = \"Ordered\";\n"+
+		"    return {\"statusCode\":201};\n"+
+		"}", js)
+}
+
+func TestScenario(t *testing.T) {
+	var imposterReqs []*http.Request
+	imposter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		imposterReqs = append(imposterReqs, r)
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "GET /__mbgo/scenarios/new%20order":
+			_, _ = w.Write([]byte(`{"state":"Ordered"}`))
+		case "DELETE /__mbgo/scenarios/new%20order":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			// the default response of an "http" Imposter
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer imposter.Close()
+
+	var reqs []*http.Request
+	mb := mbgo.NewClient(http.DefaultClient, nil, mbgo.WithAPIKey("secret"), mbgo.WithMiddleware(respondByPath(&reqs, map[string]string{
+		"POST /imposters/8080/stubs": `{"port":8080,"protocol":"http"}`,
+	})))
+
+	s, err := mb.AddScenario(context.Background(), 8080, "new order", "Started", nil)
+	assert.MustOk(t, err)
+	assert.Equals(t, 2, len(reqs))
+	assert.Equals(t, &url.URL{Scheme: "http", Host: "localhost:8080"}, s.URL)
+
+	u, err := url.Parse(imposter.URL)
+	assert.MustOk(t, err)
+	s.URL = u
+
+	state, err := s.State(context.Background())
+	assert.MustOk(t, err)
+	assert.Equals(t, "Ordered", state)
+	assert.Ok(t, s.Reset(context.Background()))
+
+	// the control requests do not go through the mountebank API client
+	assert.Equals(t, 2, len(reqs))
+	assert.Equals(t, 2, len(imposterReqs))
+	assert.Equals(t, "", imposterReqs[0].Header.Get("x-api-key"))
+
+	unknown := mb.Scenario(8080, "unknown")
+	unknown.URL = u
+	_, err = unknown.State(context.Background())
+	assert.Equals(t, &mbgo.ScenarioNotFoundError{Port: 8080, Name: "unknown"}, err)
+	assert.Equals(t, &mbgo.ScenarioNotFoundError{Port: 8080, Name: "unknown"}, unknown.Reset(context.Background()))
+}
+
+// stubServer fakes the stubs API of the "http" Imposter on port 8080, which
+// responds with the whole Imposter as mountebank does, and fails to add any
+// stub after the first failAfter ones if non-negative.
+func stubServer(stubs *[]json.RawMessage, failAfter int) mbgo.Middleware {
+	return func(next mbgo.RoundTripFunc) mbgo.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			respond := func(code int, body string) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: code,
+					Body:       ioutil.NopCloser(strings.NewReader(body)),
+					Request:    req,
+				}, nil
+			}
+
+			var index int
+			_, scanErr := fmt.Sscanf(req.URL.Path, "/imposters/8080/stubs/%d", &index)
+
+			switch {
+			case req.Method == http.MethodPost && req.URL.Path == "/imposters/8080/stubs":
+				if failAfter >= 0 && len(*stubs) >= failAfter {
+					return respond(http.StatusBadRequest, `{"errors":[{"code":"bad data","message":"invalid stub"}]}`)
+				}
+				dto := struct {
+					Index int             `json:"index"`
+					Stub  json.RawMessage `json:"stub"`
+				}{Index: -1}
+				if err := json.NewDecoder(req.Body).Decode(&dto); err != nil {
+					return nil, err
+				}
+				if dto.Index < 0 {
+					dto.Index = len(*stubs)
+				}
+				*stubs = append((*stubs)[:dto.Index], append([]json.RawMessage{dto.Stub}, (*stubs)[dto.Index:]...)...)
+			case req.Method == http.MethodDelete && scanErr == nil:
+				*stubs = append((*stubs)[:index], (*stubs)[index+1:]...)
+			default:
+				return respond(http.StatusNotFound, `{"errors":[{"code":"no such resource","message":"not found"}]}`)
+			}
+
+			b, err := json.Marshal(map[string]interface{}{"port": 8080, "protocol": "http", "stubs": *stubs})
+			if err != nil {
+				return nil, err
+			}
+			return respond(http.StatusOK, string(b))
+		}
+	}
+}
+
+func TestClient_AddScenario(t *testing.T) {
+	steps := []mbgo.ScenarioStep{
+		{
+			Predicates: []mbgo.Predicate{mbgo.Equals(mbgo.HTTPRequest{Method: http.MethodPost, Path: "/order"})},
+			Response:   mbgo.HTTPResponse{StatusCode: http.StatusCreated},
+			Next:       "Ordered",
+		},
+	}
+
+	t.Run("should insert the control stubs first and append the steps", func(t *testing.T) {
+		stubs := []json.RawMessage{json.RawMessage(`{"responses":[{"is":{"statusCode":200}}]}`)}
+		mb := mbgo.NewClient(http.DefaultClient, nil, mbgo.WithMiddleware(stubServer(&stubs, -1)))
+
+		_, err := mb.AddScenario(context.Background(), 8080, "order", "Started", steps)
+		assert.MustOk(t, err)
+		assert.Equals(t, 4, len(stubs))
+		assert.Equals(t, true, strings.Contains(string(stubs[0]), `"method":"GET","path":"/__mbgo/scenarios/order"`))
+		assert.Equals(t, true, strings.Contains(string(stubs[1]), `"method":"DELETE","path":"/__mbgo/scenarios/order"`))
+		assert.Equals(t, json.RawMessage(`{"responses":[{"is":{"statusCode":200}}]}`), stubs[2])
+		assert.Equals(t, true, strings.Contains(string(stubs[3]), `"path":"/order"`))
+	})
+
+	t.Run("should remove the added stubs if adding one fails", func(t *testing.T) {
+		stubs := []json.RawMessage{json.RawMessage(`{"responses":[{"is":{"statusCode":200}}]}`)}
+		mb := mbgo.NewClient(http.DefaultClient, nil, mbgo.WithMiddleware(stubServer(&stubs, 3)))
+
+		_, err := mb.AddScenario(context.Background(), 8080, "order", "Started", steps)
+		assert.Equals(t, errors.New("bad data: invalid stub"), err)
+		assert.Equals(t, []json.RawMessage{json.RawMessage(`{"responses":[{"is":{"statusCode":200}}]}`)}, stubs)
+	})
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SyncAction is the Client call performed by a SyncOp.
+type SyncAction string
+
+// The supported SyncAction values.
+const (
+	SyncCreate        SyncAction = "create"
+	SyncDelete        SyncAction = "delete"
+	SyncAddStub       SyncAction = "addStub"
+	SyncOverwriteStub SyncAction = "overwriteStub"
+	SyncRemoveStub    SyncAction = "removeStub"
+)
+
+// SyncOp is a single Client call of a SyncPlan.
+type SyncOp struct {
+	// Action is the Client call performed by the operation.
+	Action SyncAction
+
+	// Port is the port of the Imposter the operation applies to.
+	Port int
+
+	// Index is the Stub index used by the SyncAddStub, SyncOverwriteStub
+	// and SyncRemoveStub actions, relative to the Stubs of the Imposter
+	// once every previous operation of the plan has been applied.
+	Index int
+
+	// Imposter is the Imposter created by the SyncCreate action.
+	Imposter *Imposter
+
+	// Stub is the Stub used by the SyncAddStub and SyncOverwriteStub actions.
+	Stub *Stub
+}
+
+// String satisfies the fmt.Stringer interface.
+func (op SyncOp) String() string {
+	switch op.Action {
+	case SyncCreate:
+		return fmt.Sprintf("create imposter %d (%s, %d stubs)", op.Port, op.Imposter.Proto, len(op.Imposter.Stubs))
+	case SyncDelete:
+		return fmt.Sprintf("delete imposter %d", op.Port)
+	case SyncAddStub:
+		return fmt.Sprintf("add stub %d to imposter %d", op.Index, op.Port)
+	case SyncOverwriteStub:
+		return fmt.Sprintf("overwrite stub %d of imposter %d", op.Index, op.Port)
+	case SyncRemoveStub:
+		return fmt.Sprintf("remove stub %d of imposter %d", op.Index, op.Port)
+	default:
+		return fmt.Sprintf("%s imposter %d", op.Action, op.Port)
+	}
+}
+
+// SyncPlan is the ordered list of operations that bring the Imposters in
+// mountebank to a desired state.
+type SyncPlan struct {
+	Ops []SyncOp
+}
+
+// String satisfies the fmt.Stringer interface, listing the operations one
+// per line, or "no changes" if the plan is empty.
+func (p SyncPlan) String() string {
+	if len(p.Ops) == 0 {
+		return "no changes"
+	}
+	ss := make([]string, len(p.Ops))
+	for i, op := range p.Ops {
+		ss[i] = op.String()
+	}
+	return strings.Join(ss, "\n")
+}
+
+// PlanSync computes the operations that turn the current Imposters into the
+// desired ones. Imposters only in current are deleted and those only in
+// desired are created. Imposters on the same port are recreated if they
+// differ in anything other than their Stubs, which are otherwise updated in
+// place with the fewest stub operations, keeping any recorded requests.
+func PlanSync(current, desired []Imposter) SyncPlan {
+	cur := make(map[int]Imposter, len(current))
+	for _, imp := range current {
+		cur[imp.Port] = imp
+	}
+	des := make(map[int]Imposter, len(desired))
+	for _, imp := range desired {
+		des[imp.Port] = imp
+	}
+
+	var plan SyncPlan
+	var stubOps []SyncOp
+
+	for _, port := range sortedPorts(cur) {
+		if _, ok := des[port]; !ok {
+			plan.Ops = append(plan.Ops, SyncOp{Action: SyncDelete, Port: port})
+		}
+	}
+
+	for _, port := range sortedPorts(des) {
+		want := des[port]
+		have, ok := cur[port]
+		switch {
+		case !ok:
+			plan.Ops = append(plan.Ops, SyncOp{Action: SyncCreate, Port: port, Imposter: &want})
+		case !sameSettings(have, want):
+			plan.Ops = append(plan.Ops,
+				SyncOp{Action: SyncDelete, Port: port},
+				SyncOp{Action: SyncCreate, Port: port, Imposter: &want})
+		default:
+			stubOps = append(stubOps, planStubs(port, have.Stubs, want.Stubs)...)
+		}
+	}
+
+	plan.Ops = append(plan.Ops, stubOps...)
+	return plan
+}
+
+func sortedPorts(m map[int]Imposter) []int {
+	ports := make([]int, 0, len(m))
+	for port := range m {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// sameSettings reports whether two Imposters are equivalent apart from their
+// Stubs and recorded requests, which can change without a restart.
+func sameSettings(a, b Imposter) bool {
+	a.Stubs, a.Requests, a.RequestCount = nil, nil, 0
+	b.Stubs, b.Requests, b.RequestCount = nil, nil, 0
+	return len(Diff(a, b)) == 0
+}
+
+func stubsEqual(a, b Stub) bool {
+	var changes []Change
+	diffRecurse("", normaliseJSON("", normaliseValue(a)), normaliseJSON("", normaliseValue(b)), &changes)
+	return len(changes) == 0
+}
+
+// planStubs returns the stub operations turning have into want, based on the
+// longest common subsequence of unchanged stubs. Runs of removed stubs
+// followed by added ones are paired up into overwrites.
+func planStubs(port int, have, want []Stub) []SyncOp {
+	n, m := len(have), len(want)
+
+	// lcs[i][j] is the length of the longest common subsequence of have[i:] and want[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if stubsEqual(have[i], want[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []SyncOp
+	pos := 0
+	var removed int
+	var added []int
+
+	// flush emits the pending run of removals and additions at pos
+	flush := func() {
+		paired := removed
+		if len(added) < paired {
+			paired = len(added)
+		}
+		for k := 0; k < paired; k++ {
+			stub := want[added[k]]
+			ops = append(ops, SyncOp{Action: SyncOverwriteStub, Port: port, Index: pos, Stub: &stub})
+			pos++
+		}
+		for k := paired; k < removed; k++ {
+			ops = append(ops, SyncOp{Action: SyncRemoveStub, Port: port, Index: pos})
+		}
+		for k := paired; k < len(added); k++ {
+			stub := want[added[k]]
+			ops = append(ops, SyncOp{Action: SyncAddStub, Port: port, Index: pos, Stub: &stub})
+			pos++
+		}
+		removed, added = 0, nil
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && stubsEqual(have[i], want[j]):
+			flush()
+			pos++
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			removed++
+			i++
+		default:
+			added = append(added, j)
+			j++
+		}
+	}
+	flush()
+
+	return ops
+}
+
+// Sync brings the Imposters registered in mountebank to the desired state
+// using the operations computed by PlanSync, unlike Overwrite which restarts
+// every Imposter and loses their recorded requests. The operations are
+// applied in order, stopping at the first error, unless dryRun is true in
+// which case the plan is returned without any changes being made.
+func (cli *Client) Sync(ctx context.Context, desired []Imposter, dryRun bool) (*SyncPlan, error) {
+	current, err := cli.Imposters(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := PlanSync(current, desired)
+	if dryRun {
+		return &plan, nil
+	}
+
+	for _, op := range plan.Ops {
+		switch op.Action {
+		case SyncCreate:
+			_, err = cli.Create(ctx, *op.Imposter)
+		case SyncDelete:
+			_, err = cli.Delete(ctx, op.Port, false)
+		case SyncAddStub:
+			_, err = cli.AddStub(ctx, op.Port, op.Index, *op.Stub)
+		case SyncOverwriteStub:
+			_, err = cli.OverwriteStub(ctx, op.Port, op.Index, *op.Stub)
+		case SyncRemoveStub:
+			_, err = cli.RemoveStub(ctx, op.Port, op.Index)
+		}
+		if err != nil {
+			return &plan, fmt.Errorf("%s: %v", op, err)
+		}
+	}
+	return &plan, nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+)
+
+func stubWithStatus(code int) mbgo.Stub {
+	return mbgo.Stub{
+		Responses: []mbgo.Response{
+			{Type: "is", Value: mbgo.HTTPResponse{StatusCode: code}},
+		},
+	}
+}
+
+func TestPlanSync(t *testing.T) {
+	a, b, c, d := stubWithStatus(200), stubWithStatus(201), stubWithStatus(202), stubWithStatus(203)
+
+	cases := []struct {
+		Description string
+		Current     []mbgo.Imposter
+		Desired     []mbgo.Imposter
+		Expected    string
+	}{
+		{
+			Description: "should do nothing if the imposters are equivalent",
+			Current: []mbgo.Imposter{
+				{Port: 8080, Proto: "http", RequestCount: 3, Stubs: []mbgo.Stub{a, b}},
+			},
+			Desired: []mbgo.Imposter{
+				{Port: 8080, Proto: "http", Stubs: []mbgo.Stub{a, b}},
+			},
+			Expected: "no changes",
+		},
+		{
+			Description: "should create missing imposters and delete unwanted ones",
+			Current: []mbgo.Imposter{
+				{Port: 8080, Proto: "http"},
+			},
+			Desired: []mbgo.Imposter{
+				{Port: 8081, Proto: "tcp"},
+			},
+			Expected: "delete imposter 8080\ncreate imposter 8081 (tcp, 0 stubs)",
+		},
+		{
+			Description: "should recreate imposters whose settings changed",
+			Current: []mbgo.Imposter{
+				{Port: 8080, Proto: "http", Name: "old", Stubs: []mbgo.Stub{a}},
+			},
+			Desired: []mbgo.Imposter{
+				{Port: 8080, Proto: "http", Name: "new", Stubs: []mbgo.Stub{a}},
+			},
+			Expected: "delete imposter 8080\ncreate imposter 8080 (http, 1 stubs)",
+		},
+		{
+			Description: "should update stubs in place with the fewest operations",
+			Current: []mbgo.Imposter{
+				{Port: 8080, Proto: "http", Stubs: []mbgo.Stub{a, b, c}},
+			},
+			Desired: []mbgo.Imposter{
+				{Port: 8080, Proto: "http", Stubs: []mbgo.Stub{d, a, c, b}},
+			},
+			Expected: "add stub 0 to imposter 8080\nremove stub 2 of imposter 8080\nadd stub 3 to imposter 8080",
+		},
+		{
+			Description: "should overwrite replaced stubs",
+			Current: []mbgo.Imposter{
+				{Port: 8080, Proto: "http", Stubs: []mbgo.Stub{a, b, c}},
+			},
+			Desired: []mbgo.Imposter{
+				{Port: 8080, Proto: "http", Stubs: []mbgo.Stub{a, d}},
+			},
+			Expected: "overwrite stub 1 of imposter 8080\nremove stub 2 of imposter 8080",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.Description, func(t *testing.T) {
+			t.Parallel()
+
+			plan := mbgo.PlanSync(c.Current, c.Desired)
+			assert.Equals(t, c.Expected, plan.String())
+		})
+	}
+}
+
+func TestPlanSync_Stubs(t *testing.T) {
+	a, b := stubWithStatus(http.StatusOK), stubWithStatus(http.StatusNotFound)
+
+	plan := mbgo.PlanSync(
+		[]mbgo.Imposter{{Port: 8080, Proto: "http", Stubs: []mbgo.Stub{a}}},
+		[]mbgo.Imposter{{Port: 8080, Proto: "http", Stubs: []mbgo.Stub{a, b}}},
+	)
+	assert.Equals(t, []mbgo.SyncOp{
+		{Action: mbgo.SyncAddStub, Port: 8080, Index: 1, Stub: &b},
+	}, plan.Ops)
+}
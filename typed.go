@@ -0,0 +1,116 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+import (
+	"context"
+	"fmt"
+)
+
+// TypedImposter is a view of an Imposter whose recorded requests are of type
+// Req and whose "is" responses are of type Resp, such as an HTTPImposter.
+type TypedImposter[Req, Resp any] struct {
+	// Imposter is the untyped Imposter the view was built from.
+	Imposter Imposter
+
+	// Requests are the requests recorded by the Imposter.
+	Requests []Req
+
+	// Stubs are the typed views of the Imposter stubs, in order.
+	Stubs []TypedStub[Resp]
+}
+
+// TypedStub is a view of a Stub whose "is" responses are of type Resp.
+type TypedStub[Resp any] struct {
+	// Stub is the untyped Stub the view was built from.
+	Stub Stub
+
+	// Responses are the values of the "is" responses of the Stub, in order.
+	// Other responses, such as proxies, are only available from the Stub.
+	Responses []Resp
+}
+
+// HTTPImposter is the TypedImposter of an "http" or "https" Imposter.
+type HTTPImposter = TypedImposter[HTTPRequest, HTTPResponse]
+
+// TCPImposter is the TypedImposter of a "tcp" Imposter.
+type TCPImposter = TypedImposter[TCPRequest, TCPResponse]
+
+// NewTypedImposter returns the typed view of imp, or an error if any of its
+// recorded requests is not of type Req or *Req, or any of its "is" responses
+// is not of type Resp or *Resp.
+func NewTypedImposter[Req, Resp any](imp Imposter) (*TypedImposter[Req, Resp], error) {
+	t := &TypedImposter[Req, Resp]{
+		Imposter: imp,
+		Requests: make([]Req, 0, len(imp.Requests)),
+		Stubs:    make([]TypedStub[Resp], 0, len(imp.Stubs)),
+	}
+
+	for i, r := range imp.Requests {
+		req, ok := typedValue[Req](r)
+		if !ok {
+			return nil, fmt.Errorf("unexpected request type at /requests/%d: %T", i, r)
+		}
+		t.Requests = append(t.Requests, req)
+	}
+
+	for i, s := range imp.Stubs {
+		stub := TypedStub[Resp]{Stub: s}
+		for j, r := range s.Responses {
+			if r.Type != "is" {
+				continue
+			}
+			resp, ok := typedValue[Resp](r.Value)
+			if !ok {
+				return nil, fmt.Errorf("unexpected response type at /stubs/%d/responses/%d/is: %T", i, j, r.Value)
+			}
+			stub.Responses = append(stub.Responses, resp)
+		}
+		t.Stubs = append(t.Stubs, stub)
+	}
+
+	return t, nil
+}
+
+// typedValue asserts that v is of type T or a non-nil *T.
+func typedValue[T any](v interface{}) (T, bool) {
+	switch t := v.(type) {
+	case T:
+		return t, true
+	case *T:
+		if t != nil {
+			return *t, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// GetHTTPImposter retrieves the Imposter on the given port like Imposter,
+// returning its typed view. It returns an error if the Imposter is not of the
+// "http" or "https" protocol.
+func (cli *Client) GetHTTPImposter(ctx context.Context, port int) (*HTTPImposter, error) {
+	imp, err := cli.Imposter(ctx, port, false)
+	if err != nil {
+		return nil, err
+	}
+	if imp.Proto != "http" && imp.Proto != "https" {
+		return nil, fmt.Errorf("imposter on port %d is of protocol %q, not http", port, imp.Proto)
+	}
+	return NewTypedImposter[HTTPRequest, HTTPResponse](*imp)
+}
+
+// GetTCPImposter retrieves the Imposter on the given port like Imposter,
+// returning its typed view. It returns an error if the Imposter is not of the
+// "tcp" protocol.
+func (cli *Client) GetTCPImposter(ctx context.Context, port int) (*TCPImposter, error) {
+	imp, err := cli.Imposter(ctx, port, false)
+	if err != nil {
+		return nil, err
+	}
+	if imp.Proto != "tcp" {
+		return nil, fmt.Errorf("imposter on port %d is of protocol %q, not tcp", port, imp.Proto)
+	}
+	return NewTypedImposter[TCPRequest, TCPResponse](*imp)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+)
+
+func TestClient_GetHTTPImposter(t *testing.T) {
+	var reqs []*http.Request
+	mb := mbgo.NewClient(http.DefaultClient, nil, mbgo.WithMiddleware(respondByPath(&reqs, map[string]string{
+		"GET /imposters/8080": `{
+			"port": 8080,
+			"protocol": "http",
+			"requests": [{"method": "GET", "path": "/foo"}],
+			"stubs": [
+				{
+					"responses": [
+						{"is": {"statusCode": 200}},
+						{"proxy": {"to": "http://localhost:8081"}},
+						{"is": {"statusCode": 404}}
+					]
+				}
+			]
+		}`,
+		"GET /imposters/8081": `{"port": 8081, "protocol": "tcp"}`,
+	})))
+
+	imp, err := mb.GetHTTPImposter(context.Background(), 8080)
+	assert.MustOk(t, err)
+	assert.Equals(t, []mbgo.HTTPRequest{{Method: http.MethodGet, Path: "/foo"}}, imp.Requests)
+	assert.Equals(t, 1, len(imp.Stubs))
+	assert.Equals(t, []mbgo.HTTPResponse{
+		{StatusCode: http.StatusOK},
+		{StatusCode: http.StatusNotFound},
+	}, imp.Stubs[0].Responses)
+	assert.Equals(t, 3, len(imp.Stubs[0].Stub.Responses))
+	assert.Equals(t, 8080, imp.Imposter.Port)
+
+	_, err = mb.GetHTTPImposter(context.Background(), 8081)
+	assert.Equals(t, errors.New(`imposter on port 8081 is of protocol "tcp", not http`), err)
+
+	tcp, err := mb.GetTCPImposter(context.Background(), 8081)
+	assert.MustOk(t, err)
+	assert.Equals(t, []mbgo.TCPRequest{}, tcp.Requests)
+}
+
+func TestNewTypedImposter(t *testing.T) {
+	imp := mbgo.Imposter{
+		Port:     8080,
+		Proto:    "tcp",
+		Requests: []interface{}{mbgo.TCPRequest{Data: "foo"}, &mbgo.TCPRequest{Data: "bar"}},
+		Stubs: []mbgo.Stub{
+			{Responses: []mbgo.Response{{Type: "is", Value: mbgo.TCPResponse{Data: "baz"}}}},
+		},
+	}
+
+	typed, err := mbgo.NewTypedImposter[mbgo.TCPRequest, mbgo.TCPResponse](imp)
+	assert.MustOk(t, err)
+	assert.Equals(t, []mbgo.TCPRequest{{Data: "foo"}, {Data: "bar"}}, typed.Requests)
+	assert.Equals(t, []mbgo.TCPResponse{{Data: "baz"}}, typed.Stubs[0].Responses)
+
+	_, err = mbgo.NewTypedImposter[mbgo.HTTPRequest, mbgo.HTTPResponse](imp)
+	assert.Equals(t, errors.New("unexpected request type at /requests/0: mbgo.TCPRequest"), err)
+}
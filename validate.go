@@ -0,0 +1,295 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldError describes a single invalid field found by validation.
+type FieldError struct {
+	// Path is the JSON-pointer-like location of the invalid field relative
+	// to the validated value, e.g. "/stubs/0/predicates/1/and/0".
+	Path string
+
+	// Reason describes why the field is invalid.
+	Reason string
+}
+
+// Error satisfies the error interface.
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Reason
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Reason)
+}
+
+// ValidationError is returned by the Validate methods, and by Client.Create
+// and Client.Overwrite when using WithValidation, listing every invalid field.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error satisfies the error interface.
+func (e *ValidationError) Error() string {
+	ss := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		ss[i] = fe.Error()
+	}
+	return "invalid imposter: " + strings.Join(ss, "; ")
+}
+
+// validator accumulates FieldError values while walking a value.
+type validator struct {
+	errs []FieldError
+}
+
+func (v *validator) add(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{
+		Path:   path,
+		Reason: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errs}
+}
+
+func pathIndex(path string, i int) string {
+	return path + "/" + strconv.Itoa(i)
+}
+
+// Validate checks the Imposter for errors mountebank would reject it for,
+// along with values mbgo is unable to marshal, such as a TCPRequest used in
+// the Predicate of an "http" Imposter. It returns a *ValidationError listing
+// every invalid field, or nil if the Imposter is valid.
+func (imp Imposter) Validate() error {
+	var v validator
+	v.imposter(imp)
+	return v.err()
+}
+
+// Validate checks the Stub and its Predicates and Responses for errors
+// independently of the protocol of its Imposter. It returns a
+// *ValidationError listing every invalid field, or nil if the Stub is valid.
+func (s Stub) Validate() error {
+	var v validator
+	v.stub("", "", s)
+	return v.err()
+}
+
+// Validate checks that the Predicate uses a known operator and a request
+// value of the matching type, independently of the protocol of its Imposter.
+// It returns a *ValidationError listing every invalid field, or nil if the
+// Predicate is valid.
+func (p Predicate) Validate() error {
+	var v validator
+	v.predicate("", "", p)
+	return v.err()
+}
+
+func (v *validator) imposter(imp Imposter) {
+	if imp.Port < 1 || imp.Port > 65535 {
+		v.add("/port", "port must be between 1 and 65535, got %d", imp.Port)
+	}
+
+	switch imp.Proto {
+	case "http", "https", "tcp":
+	case "":
+		v.add("/protocol", "protocol is required")
+	default:
+		v.add("/protocol", "unsupported protocol %q", imp.Proto)
+	}
+
+	switch imp.Mode {
+	case "", ModeText, ModeBinary:
+		if imp.Mode != "" && imp.Proto != "tcp" {
+			v.add("/mode", "mode can only be used with the tcp protocol")
+		}
+	default:
+		v.add("/mode", "mode must be one of \"text\" or \"binary\", got %q", imp.Mode)
+	}
+
+	if imp.EndOfRequestResolver != "" && imp.Proto != "tcp" {
+		v.add("/endOfRequestResolver", "endOfRequestResolver can only be used with the tcp protocol")
+	}
+
+	if imp.DefaultResponse != nil {
+		v.responseValue("/defaultResponse", imp.Proto, imp.DefaultResponse)
+	}
+
+	for i, s := range imp.Stubs {
+		v.stub(pathIndex("/stubs", i), imp.Proto, s)
+	}
+}
+
+func (v *validator) stub(path, proto string, s Stub) {
+	for i, p := range s.Predicates {
+		v.predicate(pathIndex(path+"/predicates", i), proto, p)
+	}
+	for i, r := range s.Responses {
+		v.response(pathIndex(path+"/responses", i), proto, r)
+	}
+}
+
+func (v *validator) predicate(path, proto string, p Predicate) {
+	path = path + "/" + p.Operator
+
+	switch p.Operator {
+	case OpEquals, OpDeepEquals, OpContains, OpStartsWith, OpEndsWith, OpMatches:
+		switch p.Request.(type) {
+		case HTTPExistsRequest, *HTTPExistsRequest:
+			v.add(path, "HTTPExistsRequest can only be used with the exists operator")
+		default:
+			v.requestValue(path, proto, p.Request)
+		}
+
+	case OpExists:
+		switch p.Request.(type) {
+		case map[string]bool:
+		case HTTPExistsRequest, *HTTPExistsRequest:
+			if proto == "tcp" {
+				v.add(path, "HTTPExistsRequest cannot be used with the tcp protocol")
+			}
+		default:
+			v.requestValue(path, proto, p.Request)
+		}
+
+	case OpAnd, OpOr:
+		ps, ok := p.Request.([]Predicate)
+		if !ok {
+			v.add(path, "%s operator requires a []Predicate request, got %s", p.Operator, typeName(p.Request))
+			return
+		}
+		if len(ps) == 0 {
+			v.add(path, "%s operator requires at least one predicate", p.Operator)
+		}
+		for i, sub := range ps {
+			v.predicate(pathIndex(path, i), proto, sub)
+		}
+
+	case OpNot:
+		switch t := p.Request.(type) {
+		case Predicate:
+			v.predicate(path, proto, t)
+		case *Predicate:
+			if t == nil {
+				v.add(path, "not operator requires a non-nil predicate")
+				return
+			}
+			v.predicate(path, proto, *t)
+		default:
+			v.add(path, "not operator requires a Predicate request, got %s", typeName(p.Request))
+		}
+
+	case OpInject:
+		js, ok := p.Request.(string)
+		if !ok {
+			v.add(path, "inject operator requires a string request, got %s", typeName(p.Request))
+		} else if strings.TrimSpace(js) == "" {
+			v.add(path, "inject operator requires a JavaScript function")
+		}
+
+	case "":
+		v.add(path, "operator is required")
+
+	default:
+		v.add(path, "unknown operator %q", p.Operator)
+	}
+
+	if p.JSONPath != nil && p.JSONPath.Selector == "" {
+		v.add(path, "jsonpath selector is required when a JSONPath is given")
+	}
+}
+
+// requestValue checks that a predicate request value can be marshalled and
+// matches the Imposter protocol, if known.
+func (v *validator) requestValue(path, proto string, r interface{}) {
+	switch r.(type) {
+	case HTTPRequest, *HTTPRequest:
+		if proto == "tcp" {
+			v.add(path, "HTTPRequest cannot be used with the tcp protocol")
+		}
+	case TCPRequest, *TCPRequest:
+		if proto == "http" || proto == "https" {
+			v.add(path, "TCPRequest cannot be used with the %s protocol", proto)
+		}
+	case json.Marshaler:
+		// custom request values are assumed to be valid
+	default:
+		v.add(path, "unsupported predicate request type %s", typeName(r))
+	}
+}
+
+func (v *validator) response(path, proto string, r Response) {
+	switch r.Type {
+	case "is":
+		v.responseValue(path+"/is", proto, r.Value)
+	case "proxy", "inject", "fault":
+		if _, ok := r.Value.(json.Marshaler); !ok {
+			v.add(path+"/"+r.Type, "response value must implement json.Marshaler, got %s", typeName(r.Value))
+		}
+	case "":
+		v.add(path, "response type is required")
+	default:
+		v.add(path, "unknown response type %q", r.Type)
+	}
+
+	if r.Behaviors != nil && r.Behaviors.Wait < 0 {
+		v.add(path+"/_behaviors/wait", "wait must not be negative")
+	}
+}
+
+// responseValue checks that an "is" response value, or a default response,
+// can be marshalled and matches the Imposter protocol, if known.
+func (v *validator) responseValue(path, proto string, r interface{}) {
+	switch t := r.(type) {
+	case HTTPResponse:
+		v.httpResponse(path, proto, t)
+	case *HTTPResponse:
+		if t == nil {
+			v.add(path, "response value is required")
+			return
+		}
+		v.httpResponse(path, proto, *t)
+	case TCPResponse, *TCPResponse:
+		if proto == "http" || proto == "https" {
+			v.add(path, "TCPResponse cannot be used with the %s protocol", proto)
+		}
+	case json.Marshaler:
+		// custom response values are assumed to be valid
+	case nil:
+		v.add(path, "response value is required")
+	default:
+		v.add(path, "response value must implement json.Marshaler, got %s", typeName(r))
+	}
+}
+
+func (v *validator) httpResponse(path, proto string, r HTTPResponse) {
+	if proto == "tcp" {
+		v.add(path, "HTTPResponse cannot be used with the tcp protocol")
+	}
+	if r.StatusCode != 0 && (r.StatusCode < 100 || r.StatusCode > 599) {
+		v.add(path+"/statusCode", "invalid HTTP status code %d", r.StatusCode)
+	}
+	switch r.Mode {
+	case "", ModeText, ModeBinary:
+	default:
+		v.add(path+"/_mode", "mode must be one of \"text\" or \"binary\", got %q", r.Mode)
+	}
+}
+
+func typeName(v interface{}) string {
+	if v == nil {
+		return "nil"
+	}
+	return reflect.TypeOf(v).String()
+}
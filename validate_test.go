@@ -0,0 +1,166 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package mbgo_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+)
+
+func TestImposter_Validate(t *testing.T) {
+	cases := []struct {
+		Description string
+		Imposter    mbgo.Imposter
+		Expected    []mbgo.FieldError
+	}{
+		{
+			Description: "should accept a valid http imposter",
+			Imposter: mbgo.Imposter{
+				Port:  8080,
+				Proto: "http",
+				Stubs: []mbgo.Stub{
+					{
+						Predicates: []mbgo.Predicate{
+							{
+								Operator: "and",
+								Request: []mbgo.Predicate{
+									{Operator: "equals", Request: mbgo.HTTPRequest{Method: http.MethodGet}},
+									{Operator: "not", Request: mbgo.Predicate{Operator: "inject", Request: "config => false"}},
+								},
+							},
+						},
+						Responses: []mbgo.Response{
+							{Type: "is", Value: mbgo.HTTPResponse{StatusCode: http.StatusOK, Mode: "binary"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			Description: "should require the port and protocol",
+			Imposter:    mbgo.Imposter{},
+			Expected: []mbgo.FieldError{
+				{Path: "/port", Reason: "port must be between 1 and 65535, got 0"},
+				{Path: "/protocol", Reason: "protocol is required"},
+			},
+		},
+		{
+			Description: "should report every invalid predicate and response with its path",
+			Imposter: mbgo.Imposter{
+				Port:            8080,
+				Proto:           "http",
+				DefaultResponse: mbgo.TCPResponse{Data: "foo"},
+				Stubs: []mbgo.Stub{
+					{
+						Predicates: []mbgo.Predicate{
+							{Operator: "is", Request: mbgo.HTTPRequest{}},
+							{Operator: "or", Request: []mbgo.Predicate{
+								{Operator: "equals", Request: mbgo.TCPRequest{Data: "foo"}},
+							}},
+							{Operator: "and", Request: mbgo.Predicate{}},
+							{Operator: "inject", Request: 42},
+							{Operator: "contains", Request: map[string]string{"path": "/foo"}},
+						},
+						Responses: []mbgo.Response{
+							{Type: "is", Value: mbgo.HTTPResponse{StatusCode: 42, Mode: "base64"}},
+							{Type: "was", Value: mbgo.HTTPResponse{}},
+						},
+					},
+				},
+			},
+			Expected: []mbgo.FieldError{
+				{Path: "/defaultResponse", Reason: "TCPResponse cannot be used with the http protocol"},
+				{Path: "/stubs/0/predicates/0/is", Reason: `unknown operator "is"`},
+				{Path: "/stubs/0/predicates/1/or/0/equals", Reason: "TCPRequest cannot be used with the http protocol"},
+				{Path: "/stubs/0/predicates/2/and", Reason: "and operator requires a []Predicate request, got mbgo.Predicate"},
+				{Path: "/stubs/0/predicates/3/inject", Reason: "inject operator requires a string request, got int"},
+				{Path: "/stubs/0/predicates/4/contains", Reason: "unsupported predicate request type map[string]string"},
+				{Path: "/stubs/0/responses/0/is/statusCode", Reason: "invalid HTTP status code 42"},
+				{Path: "/stubs/0/responses/0/is/_mode", Reason: `mode must be one of "text" or "binary", got "base64"`},
+				{Path: "/stubs/0/responses/1", Reason: `unknown response type "was"`},
+			},
+		},
+		{
+			Description: "should reject http values on a tcp imposter",
+			Imposter: mbgo.Imposter{
+				Port:  8080,
+				Proto: "tcp",
+				Stubs: []mbgo.Stub{
+					{
+						Predicates: []mbgo.Predicate{{Operator: "equals", Request: &mbgo.HTTPRequest{}}},
+						Responses:  []mbgo.Response{{Type: "is", Value: &mbgo.HTTPResponse{}}},
+					},
+				},
+			},
+			Expected: []mbgo.FieldError{
+				{Path: "/stubs/0/predicates/0/equals", Reason: "HTTPRequest cannot be used with the tcp protocol"},
+				{Path: "/stubs/0/responses/0/is", Reason: "HTTPResponse cannot be used with the tcp protocol"},
+			},
+		},
+		{
+			Description: "should only accept a known mode on tcp imposters",
+			Imposter:    mbgo.Imposter{Port: 8080, Proto: "http", Mode: mbgo.ModeBinary},
+			Expected: []mbgo.FieldError{
+				{Path: "/mode", Reason: "mode can only be used with the tcp protocol"},
+			},
+		},
+		{
+			Description: "should reject an unknown mode",
+			Imposter:    mbgo.Imposter{Port: 8080, Proto: "tcp", Mode: "hex"},
+			Expected: []mbgo.FieldError{
+				{Path: "/mode", Reason: "mode must be one of \"text\" or \"binary\", got \"hex\""},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.Description, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.Imposter.Validate()
+			if c.Expected == nil {
+				assert.Ok(t, err)
+				return
+			}
+			var ve *mbgo.ValidationError
+			assert.Equals(t, true, errors.As(err, &ve))
+			assert.Equals(t, c.Expected, ve.Errors)
+		})
+	}
+}
+
+func TestPredicate_Validate(t *testing.T) {
+	err := mbgo.Predicate{Operator: "not", Request: []mbgo.Predicate{}}.Validate()
+	assert.Equals(t, &mbgo.ValidationError{
+		Errors: []mbgo.FieldError{
+			{Path: "/not", Reason: "not operator requires a Predicate request, got []mbgo.Predicate"},
+		},
+	}, err)
+}
+
+func TestClient_Create_WithValidation(t *testing.T) {
+	// the client should fail before ever sending a request to this address
+	mb := mbgo.NewClient(http.DefaultClient, &url.URL{Scheme: "http", Host: "invalid.invalid"}, mbgo.WithValidation())
+
+	_, err := mb.Create(context.Background(), mbgo.Imposter{Proto: "http"})
+	var ve *mbgo.ValidationError
+	assert.Equals(t, true, errors.As(err, &ve))
+	assert.Equals(t, []mbgo.FieldError{
+		{Path: "/port", Reason: "port must be between 1 and 65535, got 0"},
+	}, ve.Errors)
+
+	_, err = mb.Overwrite(context.Background(), []mbgo.Imposter{{Port: 8080, Proto: "http"}, {Port: 8081}})
+	assert.Equals(t, true, errors.As(err, &ve))
+	assert.Equals(t, []mbgo.FieldError{
+		{Path: "/imposters/1/protocol", Reason: "protocol is required"},
+	}, ve.Errors)
+}
@@ -0,0 +1,492 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package wiremock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/senseyeio/mbgo"
+)
+
+type matcherDTO map[string]interface{}
+
+type requestDTO struct {
+	Method          string                `json:"method"`
+	URLPath         string                `json:"urlPath,omitempty"`
+	URLPathPattern  string                `json:"urlPathPattern,omitempty"`
+	QueryParameters map[string]matcherDTO `json:"queryParameters,omitempty"`
+	Headers         map[string]matcherDTO `json:"headers,omitempty"`
+	BodyPatterns    []matcherDTO          `json:"bodyPatterns,omitempty"`
+}
+
+type responseDTO struct {
+	Status                 int                    `json:"status"`
+	Headers                map[string]interface{} `json:"headers,omitempty"`
+	Body                   string                 `json:"body,omitempty"`
+	JSONBody               interface{}            `json:"jsonBody,omitempty"`
+	Base64Body             string                 `json:"base64Body,omitempty"`
+	FixedDelayMilliseconds int                    `json:"fixedDelayMilliseconds,omitempty"`
+}
+
+type exportMappingDTO struct {
+	Priority              int         `json:"priority"`
+	ScenarioName          string      `json:"scenarioName,omitempty"`
+	RequiredScenarioState string      `json:"requiredScenarioState,omitempty"`
+	NewScenarioState      string      `json:"newScenarioState,omitempty"`
+	Request               requestDTO  `json:"request"`
+	Response              responseDTO `json:"response"`
+}
+
+// Export converts the Stubs of an "http" Imposter into a WireMock mapping
+// document of the form {"mappings": [...]}.
+//
+// Mappings are given increasing priorities to preserve the order in which
+// mountebank evaluates the Stubs. A Stub with several Responses becomes one
+// mapping per Response, chained together by a scenario that cycles back to
+// its first state to mimic the mountebank response queue.
+//
+// Constructs without a WireMock equivalent are not converted; if any are
+// found, the document converted from the remaining Stubs is returned with
+// an *UnsupportedError listing them.
+func Export(stubs []mbgo.Stub) ([]byte, error) {
+	var is issues
+	var mappings []exportMappingDTO
+
+	for i, stub := range stubs {
+		path := fmt.Sprintf("stubs[%d]", i)
+
+		req, sc, err := exportPredicates(path+".predicates", stub.Predicates, &is)
+		if err != nil {
+			return nil, err
+		}
+
+		resps := stub.Responses
+		if len(resps) == 0 {
+			// mountebank sends its default response if none are defined
+			resps = []mbgo.Response{{Type: "is", Value: mbgo.HTTPResponse{StatusCode: http.StatusOK}}}
+		}
+		if sc != nil && len(resps) > 1 {
+			is.add(path+".responses", "multiple responses cannot be combined with a scenario")
+			resps = resps[:1]
+		}
+
+		for j, r := range resps {
+			resp, err := exportResponse(fmt.Sprintf("%s.responses[%d]", path, j), r, &is)
+			if err != nil {
+				return nil, err
+			}
+
+			m := exportMappingDTO{
+				Priority: len(mappings) + 1,
+				Request:  req,
+				Response: resp,
+			}
+			switch {
+			case sc != nil:
+				m.ScenarioName = sc.Name
+				m.RequiredScenarioState = sc.Required
+				m.NewScenarioState = sc.Next
+			case len(resps) > 1:
+				// Chain the responses through a scenario where the mapping
+				// of response j is only matched in state j, mimicking the
+				// circular response queue of the Stub.
+				m.ScenarioName = fmt.Sprintf("stub-%d", i)
+				m.RequiredScenarioState = responseState(j)
+				m.NewScenarioState = responseState((j + 1) % len(resps))
+			}
+			mappings = append(mappings, m)
+		}
+	}
+
+	b, err := json.MarshalIndent(map[string]interface{}{
+		"mappings": mappings,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return b, is.err()
+}
+
+func responseState(i int) string {
+	if i == 0 {
+		return defaultScenarioState
+	}
+	return fmt.Sprintf("response-%d", i+1)
+}
+
+// predicateJSON is the generic JSON form of a marshalled Predicate, which
+// lets Export handle any predicate request type that marshals as mountebank
+// expects.
+type predicateJSON struct {
+	operator      string
+	fields        map[string]interface{}
+	js            string
+	caseSensitive bool
+	selector      string
+}
+
+func decodePredicate(path string, p mbgo.Predicate, is *issues) (predicateJSON, bool) {
+	var out predicateJSON
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		is.add(path, "cannot marshal predicate: %v", err)
+		return out, false
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		is.add(path, "cannot decode predicate: %v", err)
+		return out, false
+	}
+
+	for key, raw := range m {
+		switch key {
+		case "caseSensitive":
+			_ = json.Unmarshal(raw, &out.caseSensitive)
+		case "jsonpath":
+			var jp mbgo.JSONPath
+			_ = json.Unmarshal(raw, &jp)
+			out.selector = jp.Selector
+		case "except", "xpath":
+			is.add(path+"."+key, "unsupported predicate parameter")
+			return out, false
+		default:
+			out.operator = key
+			if key == "inject" {
+				_ = json.Unmarshal(raw, &out.js)
+			} else if err := json.Unmarshal(raw, &out.fields); err != nil {
+				is.add(path+"."+key, "unsupported predicate value")
+				return out, false
+			}
+		}
+	}
+	return out, true
+}
+
+func exportPredicates(path string, preds []mbgo.Predicate, is *issues) (requestDTO, *scenario, error) {
+	req := requestDTO{Method: "ANY"}
+	var sc *scenario
+
+	for i, p := range preds {
+		pp := fmt.Sprintf("%s[%d]", path, i)
+
+		if p.Operator == "not" {
+			if err := exportNot(pp, p, &req, is); err != nil {
+				return req, nil, err
+			}
+			continue
+		}
+
+		pred, ok := decodePredicate(pp, p, is)
+		if !ok {
+			continue
+		}
+
+		switch pred.operator {
+		case "inject":
+			s, ok := parseScenario(pred.js)
+			if !ok {
+				is.add(pp, "javascript injection is not supported")
+				continue
+			}
+			sc = &s
+			continue
+		case "equals", "deepEquals", "contains", "startsWith", "endsWith", "matches", "exists":
+		default:
+			is.add(pp, "unsupported predicate operator %q", pred.operator)
+			continue
+		}
+
+		names := make([]string, 0, len(pred.fields))
+		for name := range pred.fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			exportField(pp+"."+pred.operator+"."+name, pred, name, &req, is)
+		}
+	}
+
+	return req, sc, nil
+}
+
+// exportNot converts the negated regular expressions produced for WireMock
+// "doesNotMatch" matchers, the only form of "not" predicate it can express.
+func exportNot(path string, p mbgo.Predicate, req *requestDTO, is *issues) error {
+	sub, ok := p.Request.(mbgo.Predicate)
+	if !ok {
+		if ptr, isPtr := p.Request.(*mbgo.Predicate); isPtr && ptr != nil {
+			sub, ok = *ptr, true
+		}
+	}
+	if !ok {
+		is.add(path, "unsupported not predicate")
+		return nil
+	}
+
+	pred, ok := decodePredicate(path+".not", sub, is)
+	if !ok {
+		return nil
+	}
+	if pred.operator != "matches" || len(pred.fields) != 1 {
+		is.add(path, "only negated matches predicates on a single field are supported")
+		return nil
+	}
+	for name := range pred.fields {
+		pred.operator = "doesNotMatch"
+		exportField(path+".not.matches."+name, pred, name, req, is)
+	}
+	return nil
+}
+
+func exportField(path string, pred predicateJSON, name string, req *requestDTO, is *issues) {
+	v := pred.fields[name]
+
+	switch name {
+	case "method":
+		s, ok := v.(string)
+		if !ok || pred.operator != "equals" && pred.operator != "deepEquals" {
+			is.add(path, "only equality is supported on the method")
+			return
+		}
+		req.Method = strings.ToUpper(s)
+
+	case "path":
+		s, ok := v.(string)
+		if !ok {
+			is.add(path, "path must be a string")
+			return
+		}
+		switch pred.operator {
+		case "equals", "deepEquals":
+			req.URLPath = s
+		case "exists", "doesNotMatch":
+			is.add(path, "unsupported path predicate")
+		default:
+			req.URLPathPattern = stringPattern(pred, s)
+		}
+
+	case "query", "headers":
+		kvs, ok := v.(map[string]interface{})
+		if !ok {
+			is.add(path, "expected an object")
+			return
+		}
+		if pred.operator == "deepEquals" {
+			is.add(path, "exact matching of the whole %s is not supported", name)
+			return
+		}
+		dst := &req.QueryParameters
+		if name == "headers" {
+			dst = &req.Headers
+		}
+		if *dst == nil {
+			*dst = make(map[string]matcherDTO)
+		}
+		keys := make([]string, 0, len(kvs))
+		for k := range kvs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, dup := (*dst)[k]; dup {
+				is.add(path+"."+k, "only one matcher per key is supported")
+				continue
+			}
+			m, ok := stringMatcher(path+"."+k, pred, kvs[k], is)
+			if ok {
+				(*dst)[k] = m
+			}
+		}
+
+	case "body":
+		m, ok := bodyMatcher(path, pred, v, is)
+		if ok {
+			req.BodyPatterns = append(req.BodyPatterns, m)
+		}
+
+	default:
+		is.add(path, "unsupported request field")
+	}
+}
+
+// unanchor converts a mountebank regular expression, which matches anywhere
+// in the value, to one that matches the whole value as WireMock expects.
+func unanchor(re string) string {
+	if strings.HasPrefix(re, "^(?:") && strings.HasSuffix(re, ")$") {
+		return re[len("^(?:") : len(re)-len(")$")]
+	}
+	if strings.HasPrefix(re, "^") {
+		re = re[1:]
+	} else {
+		re = ".*" + re
+	}
+	if strings.HasSuffix(re, "$") && !strings.HasSuffix(re, `\$`) {
+		re = re[:len(re)-1]
+	} else {
+		re += ".*"
+	}
+	return re
+}
+
+// stringPattern converts a string predicate to a WireMock regular expression.
+func stringPattern(pred predicateJSON, s string) string {
+	var re string
+	switch pred.operator {
+	case "contains":
+		re = ".*" + regexp.QuoteMeta(s) + ".*"
+	case "startsWith":
+		re = regexp.QuoteMeta(s) + ".*"
+	case "endsWith":
+		re = ".*" + regexp.QuoteMeta(s)
+	default:
+		re = unanchor(s)
+	}
+	if !pred.caseSensitive {
+		re = "(?i)" + re
+	}
+	return re
+}
+
+func stringMatcher(path string, pred predicateJSON, v interface{}, is *issues) (matcherDTO, bool) {
+	if pred.operator == "exists" {
+		present, ok := v.(bool)
+		if !ok {
+			is.add(path, "exists predicates require a boolean")
+			return nil, false
+		}
+		if present {
+			return matcherDTO{"matches": ".*"}, true
+		}
+		return matcherDTO{"absent": true}, true
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		is.add(path, "only string values are supported")
+		return nil, false
+	}
+
+	switch pred.operator {
+	case "equals":
+		m := matcherDTO{"equalTo": s}
+		if !pred.caseSensitive {
+			m["caseInsensitive"] = true
+		}
+		return m, true
+	case "contains":
+		if pred.caseSensitive {
+			return matcherDTO{"contains": s}, true
+		}
+	case "doesNotMatch":
+		return matcherDTO{"doesNotMatch": stringPattern(predicateJSON{caseSensitive: pred.caseSensitive}, s)}, true
+	}
+	return matcherDTO{"matches": stringPattern(pred, s)}, true
+}
+
+func bodyMatcher(path string, pred predicateJSON, v interface{}, is *issues) (matcherDTO, bool) {
+	if pred.selector != "" {
+		if pred.operator == "exists" {
+			if present, _ := v.(bool); !present {
+				is.add(path, "absent JSON paths are not supported")
+				return nil, false
+			}
+			return matcherDTO{"matchesJsonPath": pred.selector}, true
+		}
+		m, ok := stringMatcher(path, pred, v, is)
+		if !ok {
+			return nil, false
+		}
+		m["expression"] = pred.selector
+		return matcherDTO{"matchesJsonPath": map[string]interface{}(m)}, true
+	}
+
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		switch pred.operator {
+		case "equals":
+			return matcherDTO{"equalToJson": v, "ignoreExtraElements": true}, true
+		case "deepEquals":
+			return matcherDTO{"equalToJson": v}, true
+		}
+		is.add(path, "unsupported JSON body predicate")
+		return nil, false
+	}
+
+	if pred.operator == "exists" {
+		is.add(path, "exists predicates on the body are not supported")
+		return nil, false
+	}
+	return stringMatcher(path, pred, v, is)
+}
+
+func exportResponse(path string, r mbgo.Response, is *issues) (responseDTO, error) {
+	out := responseDTO{Status: http.StatusOK}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		is.add(path, "cannot marshal response: %v", err)
+		return out, nil
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return out, err
+	}
+
+	for key, raw := range m {
+		switch key {
+		case "is":
+			var v struct {
+				StatusCode int                    `json:"statusCode"`
+				Headers    map[string]interface{} `json:"headers"`
+				Body       interface{}            `json:"body"`
+				Mode       string                 `json:"_mode"`
+			}
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return out, fmt.Errorf("%s.is: %v", path, err)
+			}
+			if v.StatusCode != 0 {
+				out.Status = v.StatusCode
+			}
+			out.Headers = v.Headers
+			switch body := v.Body.(type) {
+			case nil:
+			case string:
+				if v.Mode == "binary" {
+					out.Base64Body = body
+				} else {
+					out.Body = body
+				}
+			default:
+				out.JSONBody = body
+			}
+
+		case "_behaviors":
+			var behaviors map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &behaviors); err != nil {
+				return out, fmt.Errorf("%s._behaviors: %v", path, err)
+			}
+			for name, b := range behaviors {
+				if name != "wait" {
+					is.add(path+"._behaviors."+name, "unsupported behavior")
+					continue
+				}
+				if err := json.Unmarshal(b, &out.FixedDelayMilliseconds); err != nil {
+					is.add(path+"._behaviors.wait", "only fixed delays are supported")
+				}
+			}
+
+		default:
+			is.add(path+"."+key, "unsupported response type")
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,557 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package wiremock
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/senseyeio/mbgo"
+)
+
+// defaultPriority is the priority WireMock assigns to mappings without one.
+const defaultPriority = 5
+
+// Mapping keys that carry no matching behaviour and are safe to ignore.
+var ignoredMappingKeys = map[string]bool{
+	"id":         true,
+	"uuid":       true,
+	"name":       true,
+	"persistent": true,
+	"metadata":   true,
+}
+
+type mappingDTO struct {
+	Priority              *int                       `json:"priority"`
+	ScenarioName          string                     `json:"scenarioName"`
+	RequiredScenarioState string                     `json:"requiredScenarioState"`
+	NewScenarioState      string                     `json:"newScenarioState"`
+	Request               map[string]json.RawMessage `json:"request"`
+	Response              map[string]json.RawMessage `json:"response"`
+}
+
+// Import converts a WireMock mapping document into Stub values for an
+// "http" Imposter. The document is either a single mapping or an object
+// with a "mappings" array, as stored in WireMock's mappings directory or
+// returned by its admin API.
+//
+// Stubs are ordered by mapping priority. Mappings of equal priority are
+// ordered with the last one in the document first, as WireMock gives
+// precedence to the most recently added mapping.
+//
+// Constructs without a mountebank equivalent are not converted; if any are
+// found, the Stubs converted from the rest of the document are returned with
+// an *UnsupportedError listing them.
+func Import(b []byte) ([]mbgo.Stub, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	prefix := ""
+	raws := []json.RawMessage{b}
+	if m, ok := doc["mappings"]; ok {
+		prefix = "mappings"
+		raws = nil
+		if err := json.Unmarshal(m, &raws); err != nil {
+			return nil, err
+		}
+	}
+
+	type ranked struct {
+		priority int
+		index    int
+		stub     mbgo.Stub
+	}
+
+	var is issues
+	stubs := make([]ranked, 0, len(raws))
+	for i, raw := range raws {
+		path := "mapping"
+		if prefix != "" {
+			path = fmt.Sprintf("%s[%d]", prefix, i)
+		}
+
+		var dto mappingDTO
+		if err := json.Unmarshal(raw, &dto); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		stub, err := importMapping(path, raw, dto, &is)
+		if err != nil {
+			return nil, err
+		}
+
+		priority := defaultPriority
+		if dto.Priority != nil {
+			priority = *dto.Priority
+		}
+		stubs = append(stubs, ranked{priority: priority, index: i, stub: stub})
+	}
+
+	sort.SliceStable(stubs, func(i, j int) bool {
+		if stubs[i].priority != stubs[j].priority {
+			return stubs[i].priority < stubs[j].priority
+		}
+		return stubs[i].index > stubs[j].index
+	})
+
+	out := make([]mbgo.Stub, len(stubs))
+	for i, r := range stubs {
+		out[i] = r.stub
+	}
+	return out, is.err()
+}
+
+func importMapping(path string, raw json.RawMessage, dto mappingDTO, is *issues) (mbgo.Stub, error) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &keys); err != nil {
+		return mbgo.Stub{}, err
+	}
+	for key := range keys {
+		switch key {
+		case "priority", "scenarioName", "requiredScenarioState", "newScenarioState", "request", "response":
+		default:
+			if !ignoredMappingKeys[key] {
+				is.add(path+"."+key, "unsupported mapping field")
+			}
+		}
+	}
+
+	preds, err := importRequest(path+".request", dto.Request, is)
+	if err != nil {
+		return mbgo.Stub{}, err
+	}
+	if dto.ScenarioName != "" {
+		preds = append(preds, mbgo.Predicate{
+			Operator: "inject",
+			Request: scenario{
+				Name:     dto.ScenarioName,
+				Required: dto.RequiredScenarioState,
+				Next:     dto.NewScenarioState,
+			}.javascript(),
+		})
+	}
+
+	resp, err := importResponse(path+".response", dto.Response, is)
+	if err != nil {
+		return mbgo.Stub{}, err
+	}
+
+	return mbgo.Stub{
+		Predicates: preds,
+		Responses:  []mbgo.Response{resp},
+	}, nil
+}
+
+// anchor wraps a WireMock regular expression, which must match the whole
+// value, so that it behaves the same way in a mountebank "matches" predicate.
+func anchor(re string) string {
+	return "^(?:" + re + ")$"
+}
+
+// field builds an HTTPRequest used as a predicate request value, with only the
+// given field (and key, for "query" and "headers") set to v.
+func field(name, key string, v interface{}) mbgo.HTTPRequest {
+	var r mbgo.HTTPRequest
+	switch name {
+	case "method":
+		r.Method, _ = v.(string)
+	case "path":
+		r.Path, _ = v.(string)
+	case "query":
+		r.Query = url.Values{key: {fmt.Sprint(v)}}
+	case "headers":
+		r.Headers = http.Header{key: {fmt.Sprint(v)}}
+	case "body":
+		r.Body = v
+	}
+	return r
+}
+
+// exists builds the request value of an "exists" predicate.
+func exists(name, key string, present bool) json.RawMessage {
+	var v interface{} = present
+	if key != "" {
+		v = map[string]bool{key: present}
+	}
+	b, _ := json.Marshal(map[string]interface{}{name: v})
+	return b
+}
+
+func importRequest(path string, req map[string]json.RawMessage, is *issues) ([]mbgo.Predicate, error) {
+	var preds []mbgo.Predicate
+
+	keys := make([]string, 0, len(req))
+	for key := range req {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		raw := req[key]
+		p := path + "." + key
+
+		switch key {
+		case "method":
+			var method string
+			if err := json.Unmarshal(raw, &method); err != nil {
+				return nil, fmt.Errorf("%s: %v", p, err)
+			}
+			if method != "" && method != "ANY" {
+				preds = append(preds, mbgo.Predicate{
+					Operator:      "equals",
+					Request:       field("method", "", method),
+					CaseSensitive: true,
+				})
+			}
+
+		case "url", "urlPath", "urlPattern", "urlPathPattern":
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return nil, fmt.Errorf("%s: %v", p, err)
+			}
+			pred, err := importURL(p, key, s, is)
+			if err != nil {
+				return nil, err
+			}
+			preds = append(preds, pred...)
+
+		case "queryParameters", "headers":
+			var matchers map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &matchers); err != nil {
+				return nil, fmt.Errorf("%s: %v", p, err)
+			}
+			name := "query"
+			if key == "headers" {
+				name = "headers"
+			}
+			names := make([]string, 0, len(matchers))
+			for k := range matchers {
+				names = append(names, k)
+			}
+			sort.Strings(names)
+			for _, k := range names {
+				pred, ok, err := importMatcher(p+"."+k, name, k, matchers[k], is)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					preds = append(preds, pred)
+				}
+			}
+
+		case "bodyPatterns":
+			var patterns []json.RawMessage
+			if err := json.Unmarshal(raw, &patterns); err != nil {
+				return nil, fmt.Errorf("%s: %v", p, err)
+			}
+			for i, pattern := range patterns {
+				pred, ok, err := importBodyPattern(fmt.Sprintf("%s[%d]", p, i), pattern, is)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					preds = append(preds, pred)
+				}
+			}
+
+		default:
+			is.add(p, "unsupported request matcher")
+		}
+	}
+
+	return preds, nil
+}
+
+func importURL(path, key, s string, is *issues) ([]mbgo.Predicate, error) {
+	switch key {
+	case "url":
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		preds := []mbgo.Predicate{{
+			Operator:      "equals",
+			Request:       field("path", "", u.Path),
+			CaseSensitive: true,
+		}}
+		if u.RawQuery != "" {
+			preds = append(preds, mbgo.Predicate{
+				Operator:      "deepEquals",
+				Request:       mbgo.HTTPRequest{Query: u.Query()},
+				CaseSensitive: true,
+			})
+		}
+		return preds, nil
+
+	case "urlPath":
+		return []mbgo.Predicate{{
+			Operator:      "equals",
+			Request:       field("path", "", s),
+			CaseSensitive: true,
+		}}, nil
+
+	case "urlPattern":
+		// mountebank matches paths and query parameters separately, so a
+		// pattern can only be converted if it does not span the query.
+		if strings.Contains(s, `\?`) {
+			is.add(path, "patterns matching the query string are not supported")
+			return nil, nil
+		}
+		fallthrough
+
+	default:
+		return []mbgo.Predicate{{
+			Operator:      "matches",
+			Request:       field("path", "", anchor(s)),
+			CaseSensitive: true,
+		}}, nil
+	}
+}
+
+// importMatcher converts a WireMock string value matcher on the given field
+// and key, e.g. a single entry of the request "headers" object.
+func importMatcher(path, name, key string, raw json.RawMessage, is *issues) (mbgo.Predicate, bool, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return mbgo.Predicate{}, false, fmt.Errorf("%s: %v", path, err)
+	}
+
+	caseSensitive := true
+	if b, ok := m["caseInsensitive"]; ok {
+		var insensitive bool
+		if err := json.Unmarshal(b, &insensitive); err != nil {
+			return mbgo.Predicate{}, false, fmt.Errorf("%s.caseInsensitive: %v", path, err)
+		}
+		caseSensitive = !insensitive
+		delete(m, "caseInsensitive")
+	}
+	// WireMock matches header names case-insensitively, whereas a
+	// case-sensitive mountebank predicate also applies to the names, so
+	// header values are always compared case-insensitively.
+	if name == "headers" {
+		caseSensitive = false
+	}
+	if len(m) != 1 {
+		is.add(path, "expected exactly one matcher")
+		return mbgo.Predicate{}, false, nil
+	}
+
+	for op, b := range m {
+		if op == "absent" {
+			var absent bool
+			if err := json.Unmarshal(b, &absent); err != nil {
+				return mbgo.Predicate{}, false, fmt.Errorf("%s.absent: %v", path, err)
+			}
+			return mbgo.Predicate{
+				Operator: "exists",
+				Request:  exists(name, key, !absent),
+			}, true, nil
+		}
+
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			is.add(path+"."+op, "only string matcher values are supported")
+			return mbgo.Predicate{}, false, nil
+		}
+
+		pred := mbgo.Predicate{CaseSensitive: caseSensitive}
+		switch op {
+		case "equalTo":
+			pred.Operator = "equals"
+			pred.Request = field(name, key, s)
+		case "contains":
+			pred.Operator = "contains"
+			pred.Request = field(name, key, s)
+		case "matches":
+			pred.Operator = "matches"
+			pred.Request = field(name, key, anchor(s))
+		case "doesNotMatch":
+			pred.Operator = "not"
+			pred.Request = mbgo.Predicate{
+				Operator:      "matches",
+				Request:       field(name, key, anchor(s)),
+				CaseSensitive: caseSensitive,
+			}
+			pred.CaseSensitive = false
+		default:
+			is.add(path+"."+op, "unsupported matcher")
+			return mbgo.Predicate{}, false, nil
+		}
+		return pred, true, nil
+	}
+	return mbgo.Predicate{}, false, nil
+}
+
+func importBodyPattern(path string, raw json.RawMessage, is *issues) (mbgo.Predicate, bool, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return mbgo.Predicate{}, false, fmt.Errorf("%s: %v", path, err)
+	}
+
+	if b, ok := m["equalToJson"]; ok {
+		var v interface{}
+		if err := json.Unmarshal(b, &v); err != nil {
+			return mbgo.Predicate{}, false, fmt.Errorf("%s.equalToJson: %v", path, err)
+		}
+		// equalToJson may also be given as a string containing the JSON
+		if s, ok := v.(string); ok {
+			if err := json.Unmarshal([]byte(s), &v); err != nil {
+				return mbgo.Predicate{}, false, fmt.Errorf("%s.equalToJson: %v", path, err)
+			}
+		}
+
+		op := "deepEquals"
+		for key, b := range m {
+			switch key {
+			case "equalToJson":
+			case "ignoreExtraElements":
+				var ignore bool
+				if err := json.Unmarshal(b, &ignore); err != nil {
+					return mbgo.Predicate{}, false, fmt.Errorf("%s.%s: %v", path, key, err)
+				}
+				if ignore {
+					op = "equals"
+				}
+			default:
+				is.add(path+"."+key, "unsupported equalToJson option")
+			}
+		}
+		return mbgo.Predicate{
+			Operator:      op,
+			Request:       field("body", "", v),
+			CaseSensitive: true,
+		}, true, nil
+	}
+
+	if b, ok := m["matchesJsonPath"]; ok {
+		if len(m) != 1 {
+			is.add(path, "expected exactly one body pattern")
+			return mbgo.Predicate{}, false, nil
+		}
+		return importJSONPath(path+".matchesJsonPath", b, is)
+	}
+
+	return importMatcher(path, "body", "", raw, is)
+}
+
+func importJSONPath(path string, raw json.RawMessage, is *issues) (mbgo.Predicate, bool, error) {
+	var expr string
+	if err := json.Unmarshal(raw, &expr); err == nil {
+		return mbgo.Predicate{
+			Operator: "exists",
+			Request:  exists("body", "", true),
+			JSONPath: &mbgo.JSONPath{Selector: expr},
+		}, true, nil
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return mbgo.Predicate{}, false, fmt.Errorf("%s: %v", path, err)
+	}
+	b, ok := m["expression"]
+	if !ok {
+		is.add(path, "missing expression")
+		return mbgo.Predicate{}, false, nil
+	}
+	if err := json.Unmarshal(b, &expr); err != nil {
+		return mbgo.Predicate{}, false, fmt.Errorf("%s.expression: %v", path, err)
+	}
+	delete(m, "expression")
+
+	matcher, err := json.Marshal(m)
+	if err != nil {
+		return mbgo.Predicate{}, false, err
+	}
+	pred, ok, err := importMatcher(path, "body", "", matcher, is)
+	if err != nil || !ok {
+		return pred, ok, err
+	}
+	if sub, ok := pred.Request.(mbgo.Predicate); ok {
+		sub.JSONPath = &mbgo.JSONPath{Selector: expr}
+		pred.Request = sub
+	} else {
+		pred.JSONPath = &mbgo.JSONPath{Selector: expr}
+	}
+	return pred, true, nil
+}
+
+func importResponse(path string, resp map[string]json.RawMessage, is *issues) (mbgo.Response, error) {
+	r := mbgo.HTTPResponse{StatusCode: http.StatusOK}
+	out := mbgo.Response{Type: "is"}
+
+	keys := make([]string, 0, len(resp))
+	for key := range resp {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		raw := resp[key]
+		p := path + "." + key
+
+		var err error
+		switch key {
+		case "status":
+			err = json.Unmarshal(raw, &r.StatusCode)
+
+		case "headers":
+			var hs map[string]interface{}
+			if err = json.Unmarshal(raw, &hs); err != nil {
+				break
+			}
+			r.Headers = make(http.Header, len(hs))
+			for k, v := range hs {
+				switch t := v.(type) {
+				case []interface{}:
+					for _, elem := range t {
+						r.Headers[k] = append(r.Headers[k], fmt.Sprint(elem))
+					}
+				default:
+					r.Headers[k] = []string{fmt.Sprint(t)}
+				}
+			}
+
+		case "body":
+			var s string
+			err = json.Unmarshal(raw, &s)
+			r.Body = s
+
+		case "jsonBody":
+			err = json.Unmarshal(raw, &r.Body)
+
+		case "base64Body":
+			var s string
+			if err = json.Unmarshal(raw, &s); err != nil {
+				break
+			}
+			if _, err = base64.StdEncoding.DecodeString(s); err != nil {
+				break
+			}
+			r.Body = s
+			r.Mode = "binary"
+
+		case "fixedDelayMilliseconds":
+			var wait int
+			if err = json.Unmarshal(raw, &wait); err != nil {
+				break
+			}
+			out.Behaviors = &mbgo.Behaviors{Wait: wait}
+
+		default:
+			is.add(p, "unsupported response field")
+		}
+		if err != nil {
+			return mbgo.Response{}, fmt.Errorf("%s: %v", p, err)
+		}
+	}
+
+	out.Value = r
+	return out, nil
+}
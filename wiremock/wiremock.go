@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+// Package wiremock converts between WireMock JSON stub mappings and mbgo Stub
+// values for HTTP imposters, to help migrate existing WireMock suites onto
+// mountebank.
+//
+// Request matchers on method, urlPath, urlPattern and urlPathPattern, query
+// parameters, headers and bodyPatterns (equalTo, contains, matches,
+// doesNotMatch, absent, equalToJson and matchesJsonPath) are supported, as
+// are response status, headers, body, jsonBody, base64Body and
+// fixedDelayMilliseconds. WireMock scenarios are expressed in mountebank as
+// an "inject" predicate sharing scenario state between stubs, so imposters
+// using them need mountebank to run with --allowInjection.
+//
+// See more information about the WireMock stub mapping format at:
+// http://wiremock.org/docs/stubbing.
+package wiremock
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Issue describes a single construct that could not be converted because it
+// has no equivalent on the other side of the conversion.
+type Issue struct {
+	// Path locates the construct in the source document or values,
+	// e.g. "mappings[2].request.bodyPatterns[0].equalToXml".
+	Path string
+
+	// Reason is a short description of why the construct was not converted.
+	Reason string
+}
+
+// String satisfies the fmt.Stringer interface.
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Path, i.Reason)
+}
+
+// UnsupportedError is returned by Import and Export alongside the converted
+// values when some constructs could not be converted.
+type UnsupportedError struct {
+	// Issues lists every construct that was not converted.
+	Issues []Issue
+}
+
+// Error satisfies the error interface.
+func (e *UnsupportedError) Error() string {
+	ss := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		ss[i] = issue.String()
+	}
+	return fmt.Sprintf("wiremock: %d unsupported construct(s): %s", len(e.Issues), strings.Join(ss, "; "))
+}
+
+// issues accumulates the Issue values found during a conversion.
+type issues []Issue
+
+func (is *issues) add(path, format string, args ...interface{}) {
+	*is = append(*is, Issue{
+		Path:   path,
+		Reason: fmt.Sprintf(format, args...),
+	})
+}
+
+func (is issues) err() error {
+	if len(is) == 0 {
+		return nil
+	}
+	return &UnsupportedError{Issues: is}
+}
+
+// defaultScenarioState is the state every WireMock scenario starts in.
+const defaultScenarioState = "Started"
+
+// scenario describes the WireMock scenario fields of a single mapping.
+type scenario struct {
+	Name     string `json:"name"`
+	Required string `json:"required,omitempty"`
+	Next     string `json:"next,omitempty"`
+}
+
+// scenarioPrefix marks the line holding the scenario JSON in an inject
+// predicate generated by Import, which lets Export recover it.
+const scenarioPrefix = "var scenario = "
+
+// scenarioJS is the body of the inject predicate used to emulate a WireMock
+// scenario; it is placed last in the Stub predicates so it is only evaluated,
+// and the state only transitioned, once every other predicate has matched.
+const scenarioJS = `function (config) {
+	` + scenarioPrefix + `%s;
+	var states = config.state.wiremockScenarios = config.state.wiremockScenarios || {};
+	var current = states[scenario.name] || "` + defaultScenarioState + `";
+	if (scenario.required && current !== scenario.required) {
+		return false;
+	}
+	if (scenario.next) {
+		states[scenario.name] = scenario.next;
+	}
+	return true;
+}`
+
+func (s scenario) javascript() string {
+	b, _ := json.Marshal(s)
+	return fmt.Sprintf(scenarioJS, b)
+}
+
+// parseScenario recovers the scenario from JavaScript generated by
+// scenario.javascript, reporting false if js was not generated by it.
+func parseScenario(js string) (scenario, bool) {
+	var s scenario
+	for _, line := range strings.Split(js, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, scenarioPrefix) {
+			continue
+		}
+		line = strings.TrimSuffix(strings.TrimPrefix(line, scenarioPrefix), ";")
+		if err := json.Unmarshal([]byte(line), &s); err != nil || s.Name == "" {
+			return s, false
+		}
+		return s, true
+	}
+	return s, false
+}
@@ -0,0 +1,331 @@
+// Copyright (c) 2018 Senseye Ltd. All rights reserved.
+// Use of this source code is governed by the MIT License that can be found in the LICENSE file.
+
+package wiremock_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/senseyeio/mbgo"
+	"github.com/senseyeio/mbgo/internal/assert"
+	"github.com/senseyeio/mbgo/wiremock"
+)
+
+func TestImport(t *testing.T) {
+	cases := []struct {
+		Description string
+		JSON        string
+		Expected    []mbgo.Stub
+		Issues      []wiremock.Issue
+	}{
+		{
+			Description: "should convert a single mapping with path, header and JSON body matchers",
+			JSON: `{
+				"request": {
+					"method": "POST",
+					"urlPath": "/orders",
+					"headers": {"Content-Type": {"equalTo": "application/json", "caseInsensitive": true}},
+					"bodyPatterns": [{"equalToJson": {"id": 1}, "ignoreExtraElements": true}, {"matchesJsonPath": "$.items"}]
+				},
+				"response": {
+					"status": 201,
+					"headers": {"Location": "/orders/1"},
+					"jsonBody": {"id": 1},
+					"fixedDelayMilliseconds": 250
+				}
+			}`,
+			Expected: []mbgo.Stub{
+				{
+					Predicates: []mbgo.Predicate{
+						{
+							Operator:      "equals",
+							Request:       mbgo.HTTPRequest{Body: map[string]interface{}{"id": float64(1)}},
+							CaseSensitive: true,
+						},
+						{
+							Operator: "exists",
+							Request:  json.RawMessage(`{"body":true}`),
+							JSONPath: &mbgo.JSONPath{Selector: "$.items"},
+						},
+						{
+							Operator: "equals",
+							Request:  mbgo.HTTPRequest{Headers: http.Header{"Content-Type": {"application/json"}}},
+						},
+						{
+							Operator:      "equals",
+							Request:       mbgo.HTTPRequest{Method: http.MethodPost},
+							CaseSensitive: true,
+						},
+						{
+							Operator:      "equals",
+							Request:       mbgo.HTTPRequest{Path: "/orders"},
+							CaseSensitive: true,
+						},
+					},
+					Responses: []mbgo.Response{
+						{
+							Type: "is",
+							Value: mbgo.HTTPResponse{
+								StatusCode: http.StatusCreated,
+								Headers:    http.Header{"Location": {"/orders/1"}},
+								Body:       map[string]interface{}{"id": float64(1)},
+							},
+							Behaviors: &mbgo.Behaviors{Wait: 250},
+						},
+					},
+				},
+			},
+		},
+		{
+			Description: "should not make header predicates case-sensitive",
+			JSON: `{
+				"request": {
+					"headers": {
+						"x-api-key": {"equalTo": "Secret"},
+						"Accept": {"doesNotMatch": "text/.*"}
+					}
+				},
+				"response": {"status": 204}
+			}`,
+			Expected: []mbgo.Stub{
+				{
+					Predicates: []mbgo.Predicate{
+						{
+							Operator: "not",
+							Request: mbgo.Predicate{
+								Operator: "matches",
+								Request:  mbgo.HTTPRequest{Headers: http.Header{"Accept": {"^(?:text/.*)$"}}},
+							},
+						},
+						{
+							Operator: "equals",
+							Request:  mbgo.HTTPRequest{Headers: http.Header{"x-api-key": {"Secret"}}},
+						},
+					},
+					Responses: []mbgo.Response{
+						{
+							Type:  "is",
+							Value: mbgo.HTTPResponse{StatusCode: http.StatusNoContent},
+						},
+					},
+				},
+			},
+		},
+		{
+			Description: "should order mappings by priority and report unsupported constructs",
+			JSON: `{"mappings": [
+				{
+					"request": {"urlPattern": "/a/.*", "queryParameters": {"q": {"absent": true}}},
+					"response": {"status": 404}
+				},
+				{
+					"priority": 1,
+					"request": {"url": "/b?x=1", "cookies": {"session": {"equalTo": "1"}}},
+					"response": {"base64Body": "AQI=", "proxyBaseUrl": "http://example.com"}
+				}
+			]}`,
+			Expected: []mbgo.Stub{
+				{
+					Predicates: []mbgo.Predicate{
+						{
+							Operator:      "equals",
+							Request:       mbgo.HTTPRequest{Path: "/b"},
+							CaseSensitive: true,
+						},
+						{
+							Operator:      "deepEquals",
+							Request:       mbgo.HTTPRequest{Query: url.Values{"x": {"1"}}},
+							CaseSensitive: true,
+						},
+					},
+					Responses: []mbgo.Response{
+						{
+							Type: "is",
+							Value: mbgo.HTTPResponse{
+								StatusCode: http.StatusOK,
+								Body:       "AQI=",
+								Mode:       "binary",
+							},
+						},
+					},
+				},
+				{
+					Predicates: []mbgo.Predicate{
+						{
+							Operator: "exists",
+							Request:  json.RawMessage(`{"query":{"q":false}}`),
+						},
+						{
+							Operator:      "matches",
+							Request:       mbgo.HTTPRequest{Path: "^(?:/a/.*)$"},
+							CaseSensitive: true,
+						},
+					},
+					Responses: []mbgo.Response{
+						{
+							Type:  "is",
+							Value: mbgo.HTTPResponse{StatusCode: http.StatusNotFound},
+						},
+					},
+				},
+			},
+			Issues: []wiremock.Issue{
+				{Path: "mappings[1].request.cookies", Reason: "unsupported request matcher"},
+				{Path: "mappings[1].response.proxyBaseUrl", Reason: "unsupported response field"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.Description, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := wiremock.Import([]byte(c.JSON))
+			if c.Issues != nil {
+				var ue *wiremock.UnsupportedError
+				assert.Equals(t, true, errors.As(err, &ue))
+				assert.Equals(t, c.Issues, ue.Issues)
+			} else {
+				assert.Ok(t, err)
+			}
+			assert.Equals(t, c.Expected, actual)
+		})
+	}
+}
+
+func TestImport_Scenario(t *testing.T) {
+	stubs, err := wiremock.Import([]byte(`{"mappings": [
+		{
+			"scenarioName": "order",
+			"requiredScenarioState": "Started",
+			"newScenarioState": "Created",
+			"request": {"method": "POST", "urlPath": "/order"},
+			"response": {"status": 201}
+		}
+	]}`))
+	assert.MustOk(t, err)
+	assert.Equals(t, 1, len(stubs))
+
+	last := stubs[0].Predicates[len(stubs[0].Predicates)-1]
+	assert.Equals(t, "inject", last.Operator)
+
+	// the scenario must be recovered when exporting the stubs again
+	b, err := wiremock.Export(stubs)
+	assert.MustOk(t, err)
+
+	var doc struct {
+		Mappings []map[string]interface{} `json:"mappings"`
+	}
+	assert.MustOk(t, json.Unmarshal(b, &doc))
+	assert.Equals(t, "order", doc.Mappings[0]["scenarioName"])
+	assert.Equals(t, "Started", doc.Mappings[0]["requiredScenarioState"])
+	assert.Equals(t, "Created", doc.Mappings[0]["newScenarioState"])
+}
+
+func TestExport(t *testing.T) {
+	stubs := []mbgo.Stub{
+		{
+			Predicates: []mbgo.Predicate{
+				{
+					Operator: "equals",
+					Request: mbgo.HTTPRequest{
+						Method:  http.MethodGet,
+						Path:    "/foo",
+						Headers: http.Header{"Accept": {"application/json"}},
+					},
+					CaseSensitive: true,
+				},
+				{
+					Operator: "startsWith",
+					Request:  mbgo.HTTPRequest{Query: url.Values{"q": {"a.b"}}},
+				},
+				{
+					Operator: "inject",
+					Request:  "function (config) { return true; }",
+				},
+			},
+			Responses: []mbgo.Response{
+				{
+					Type:  "is",
+					Value: mbgo.HTTPResponse{StatusCode: http.StatusOK, Body: "first"},
+				},
+				{
+					Type:      "is",
+					Value:     mbgo.HTTPResponse{StatusCode: http.StatusAccepted, Body: map[string]interface{}{"second": true}},
+					Behaviors: &mbgo.Behaviors{Wait: 10},
+				},
+			},
+		},
+	}
+
+	b, err := wiremock.Export(stubs)
+
+	var ue *wiremock.UnsupportedError
+	assert.Equals(t, true, errors.As(err, &ue))
+	assert.Equals(t, []wiremock.Issue{
+		{Path: "stubs[0].predicates[2]", Reason: "javascript injection is not supported"},
+	}, ue.Issues)
+
+	var actual, expected interface{}
+	assert.MustOk(t, json.Unmarshal(b, &actual))
+	assert.MustOk(t, json.Unmarshal([]byte(`{"mappings": [
+		{
+			"priority": 1,
+			"scenarioName": "stub-0",
+			"requiredScenarioState": "Started",
+			"newScenarioState": "response-2",
+			"request": {
+				"method": "GET",
+				"urlPath": "/foo",
+				"headers": {"Accept": {"equalTo": "application/json"}},
+				"queryParameters": {"q": {"matches": "(?i)a\\.b.*"}}
+			},
+			"response": {"status": 200, "body": "first"}
+		},
+		{
+			"priority": 2,
+			"scenarioName": "stub-0",
+			"requiredScenarioState": "response-2",
+			"newScenarioState": "Started",
+			"request": {
+				"method": "GET",
+				"urlPath": "/foo",
+				"headers": {"Accept": {"equalTo": "application/json"}},
+				"queryParameters": {"q": {"matches": "(?i)a\\.b.*"}}
+			},
+			"response": {"status": 202, "jsonBody": {"second": true}, "fixedDelayMilliseconds": 10}
+		}
+	]}`), &expected))
+	assert.Equals(t, expected, actual)
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	in := []byte(`{"mappings": [
+		{
+			"priority": 1,
+			"request": {
+				"method": "PUT",
+				"urlPathPattern": "/items/[0-9]+",
+				"queryParameters": {"force": {"doesNotMatch": "false"}},
+				"bodyPatterns": [{"matchesJsonPath": {"expression": "$.name", "contains": "widget"}}]
+			},
+			"response": {"status": 204, "headers": {"X-Trace": ["a", "b"]}}
+		}
+	]}`)
+
+	stubs, err := wiremock.Import(in)
+	assert.MustOk(t, err)
+
+	out, err := wiremock.Export(stubs)
+	assert.MustOk(t, err)
+
+	again, err := wiremock.Import(out)
+	assert.MustOk(t, err)
+	assert.Equals(t, stubs, again)
+}